@@ -0,0 +1,432 @@
+package scoutssh
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+type ArchiveFormat int
+
+const (
+	TarGz ArchiveFormat = iota
+	Zip
+)
+
+var ArchiveFormats = []ArchiveFormat{TarGz, Zip}
+
+func (f ArchiveFormat) String() string {
+	if f == Zip {
+		return "zip"
+	}
+	return "tar.gz"
+}
+
+// IsArchive reports whether name looks like an archive ExtractArchive understands.
+func IsArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateArchive packs paths, which must live in baseDir, into archivePath on the remote host.
+func CreateArchive(client *sftp.Client, sshClient *ssh.Client, archivePath, baseDir string, paths []string, format ArchiveFormat) error {
+	if _, err := client.Lstat(archivePath); err == nil {
+		return fmt.Errorf("%s already exists", archivePath)
+	}
+
+	cmd := "tar -czf " + ShellQuote(archivePath) + " --"
+	if format == Zip {
+		cmd = "zip -qry " + ShellQuote(archivePath) + " --"
+	}
+	for _, name := range relativeNames(baseDir, paths) {
+		cmd += " " + ShellQuote(name)
+	}
+	_, err := RunCommand(sshClient, "cd "+ShellQuote(baseDir)+" && "+cmd)
+	if !errors.Is(err, errNoCommand) {
+		return err
+	}
+
+	file, err := client.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %v", err)
+	}
+	if format == Zip {
+		err = WriteZip(client, file, baseDir, paths, archivePath)
+	} else {
+		err = WriteTarGz(client, file, baseDir, paths, archivePath)
+	}
+	if err != nil {
+		file.Close()
+		client.Remove(archivePath)
+		return err
+	}
+	return file.Close()
+}
+
+// StreamTarGz writes a gzip-compressed tarball of paths to w without creating anything on the remote host.
+func StreamTarGz(client *sftp.Client, sshClient *ssh.Client, w io.Writer, baseDir string, paths []string) error {
+	cmd := "tar -czf - -C " + ShellQuote(baseDir) + " --"
+	for _, name := range relativeNames(baseDir, paths) {
+		cmd += " " + ShellQuote(name)
+	}
+	err := StreamCommand(sshClient, cmd, w)
+	if !errors.Is(err, errNoCommand) {
+		return err
+	}
+	return WriteTarGz(client, w, baseDir, paths, "")
+}
+
+// ExtractArchive unpacks a remote archive into destDir.
+func ExtractArchive(client *sftp.Client, sshClient *ssh.Client, archivePath, destDir string) error {
+	if err := client.MkdirAll(destDir); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	isZip := strings.HasSuffix(strings.ToLower(archivePath), ".zip")
+	cmd := "tar -xf " + ShellQuote(archivePath) + " -C " + ShellQuote(destDir)
+	if isZip {
+		cmd = "unzip -qo " + ShellQuote(archivePath) + " -d " + ShellQuote(destDir)
+	}
+	_, err := RunCommand(sshClient, cmd)
+	if !errors.Is(err, errNoCommand) {
+		return err
+	}
+
+	file, err := client.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %v", err)
+	}
+	defer file.Close()
+
+	if isZip {
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat archive: %v", err)
+		}
+		return extractZip(client, file, info.Size(), destDir)
+	}
+
+	var reader io.Reader = file
+	if lower := strings.ToLower(archivePath); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %v", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	return extractTar(client, reader, destDir)
+}
+
+// WriteTarGz streams the remote paths into w as a gzip-compressed tarball.
+// Entry names are relative to baseDir; paths matching skip are left out.
+func WriteTarGz(client *sftp.Client, w io.Writer, baseDir string, paths []string, skip string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := walkArchived(client, paths, skip, func(entryPath string, info os.FileInfo) error {
+		return addTarEntry(client, tw, baseDir, entryPath, info)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %v", err)
+	}
+	return gz.Close()
+}
+
+// WriteZip is the zip counterpart of WriteTarGz.
+func WriteZip(client *sftp.Client, w io.Writer, baseDir string, paths []string, skip string) error {
+	zw := zip.NewWriter(w)
+
+	err := walkArchived(client, paths, skip, func(entryPath string, info os.FileInfo) error {
+		return addZipEntry(client, zw, baseDir, entryPath, info)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func walkArchived(client *sftp.Client, paths []string, skip string, add func(string, os.FileInfo) error) error {
+	for _, root := range paths {
+		walker := client.Walk(strings.TrimSuffix(root, "/"))
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				return fmt.Errorf("failed to read directory: %v", err)
+			}
+			if walker.Path() == skip {
+				continue
+			}
+			if err := add(walker.Path(), walker.Stat()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func addTarEntry(client *sftp.Client, tw *tar.Writer, baseDir, entryPath string, info os.FileInfo) error {
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, _ = client.ReadLink(entryPath)
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return fmt.Errorf("failed to create archive header: %v", err)
+	}
+	header.Name = archiveName(baseDir, entryPath, info.IsDir())
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		header.Uid, header.Gid = int(stat.UID), int(stat.GID)
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive header: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	return copyRemoteFile(client, entryPath, tw)
+}
+
+func addZipEntry(client *sftp.Client, zw *zip.Writer, baseDir, entryPath string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return fmt.Errorf("failed to create archive header: %v", err)
+	}
+	header.Name = archiveName(baseDir, entryPath, info.IsDir())
+	if info.Mode().IsRegular() {
+		header.Method = zip.Deflate
+	}
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to write archive header: %v", err)
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := client.ReadLink(entryPath)
+		if err != nil {
+			return fmt.Errorf("failed to read link: %v", err)
+		}
+		_, err = io.WriteString(w, link)
+		return err
+	case info.Mode().IsRegular():
+		return copyRemoteFile(client, entryPath, w)
+	}
+	return nil
+}
+
+func copyRemoteFile(client *sftp.Client, entryPath string, w io.Writer) error {
+	file, err := client.Open(entryPath)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to archive %s: %v", path.Base(entryPath), err)
+	}
+	return nil
+}
+
+func extractTar(client *sftp.Client, r io.Reader, destDir string) error {
+	tr := tar.NewReader(r)
+	var links []pendingLink
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return extractLinks(client, destDir, links)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %v", err)
+		}
+		target, err := extractPath(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+		mode := header.FileInfo().Mode()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = mkdirMode(client, destDir, target, mode)
+		case tar.TypeSymlink:
+			links = append(links, pendingLink{target: target, linkTarget: header.Linkname})
+		case tar.TypeReg:
+			err = extractFile(client, destDir, target, mode, tr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(client *sftp.Client, r io.ReaderAt, size int64, destDir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %v", err)
+	}
+	var links []pendingLink
+	for _, entry := range zr.File {
+		target, err := extractPath(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+		mode := entry.Mode()
+		if mode.IsDir() {
+			if err := mkdirMode(client, destDir, target, mode); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", entry.Name, err)
+		}
+		if mode&os.ModeSymlink != 0 {
+			var link []byte
+			if link, err = io.ReadAll(content); err == nil {
+				links = append(links, pendingLink{target: target, linkTarget: string(link)})
+			}
+		} else {
+			err = extractFile(client, destDir, target, mode, content)
+		}
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return extractLinks(client, destDir, links)
+}
+
+// pendingLink is a symlink entry, created only after every other entry so
+// that no later entry can be written through it.
+type pendingLink struct {
+	target, linkTarget string
+}
+
+// extractLinks creates links whose target stays inside destDir and reports the skipped rest.
+func extractLinks(client *sftp.Client, destDir string, links []pendingLink) error {
+	var skipped []string
+	for _, link := range links {
+		resolved := link.linkTarget
+		if !path.IsAbs(resolved) {
+			resolved = path.Join(path.Dir(link.target), resolved)
+		}
+		if !inside(destDir, resolved) {
+			skipped = append(skipped, link.target+" -> "+link.linkTarget)
+			continue
+		}
+		if err := extractSymlink(client, destDir, link.target, link.linkTarget); err != nil {
+			return err
+		}
+	}
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped links pointing outside the destination:\n%s", strings.Join(skipped, "\n"))
+	}
+	return nil
+}
+
+// extractPath joins name onto destDir and refuses entries that would land outside it.
+func extractPath(destDir, name string) (string, error) {
+	target := path.Join(destDir, name)
+	if !inside(destDir, target) {
+		return "", fmt.Errorf("archive entry %s points outside the destination", name)
+	}
+	return target, nil
+}
+
+func inside(destDir, target string) bool {
+	destDir = path.Clean(destDir)
+	return target == destDir || strings.HasPrefix(target, destDir+"/")
+}
+
+// checkNoLinks refuses target when it or a directory between destDir and it
+// is a symlink, since writing through the link could land outside destDir.
+func checkNoLinks(client *sftp.Client, destDir, target string) error {
+	current := path.Clean(destDir)
+	for _, part := range strings.Split(strings.TrimPrefix(target, current), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		info, err := client.Lstat(current)
+		if err != nil {
+			// Missing parts are created as plain directories.
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to extract %s through the link %s", target, current)
+		}
+	}
+	return nil
+}
+
+func mkdirMode(client *sftp.Client, destDir, target string, mode os.FileMode) error {
+	if err := checkNoLinks(client, destDir, target); err != nil {
+		return err
+	}
+	if err := client.MkdirAll(target); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+	return client.Chmod(target, mode.Perm())
+}
+
+func extractSymlink(client *sftp.Client, destDir, target, linkTarget string) error {
+	if err := checkNoLinks(client, destDir, path.Dir(target)); err != nil {
+		return err
+	}
+	client.Remove(target)
+	if err := client.Symlink(linkTarget, target); err != nil {
+		return fmt.Errorf("failed to create link %s: %v", target, err)
+	}
+	return nil
+}
+
+func extractFile(client *sftp.Client, destDir, target string, mode os.FileMode, r io.Reader) error {
+	if err := checkNoLinks(client, destDir, target); err != nil {
+		return err
+	}
+	if err := client.MkdirAll(path.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+	file, err := client.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file: %v", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, r); err != nil {
+		return fmt.Errorf("failed to extract %s: %v", path.Base(target), err)
+	}
+	return client.Chmod(target, mode.Perm())
+}
+
+func archiveName(baseDir, entryPath string, isDir bool) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(entryPath, strings.TrimSuffix(baseDir, "/")), "/")
+	if isDir {
+		name += "/"
+	}
+	return name
+}
+
+func relativeNames(baseDir string, paths []string) []string {
+	var names []string
+	for _, p := range paths {
+		names = append(names, archiveName(baseDir, strings.TrimSuffix(p, "/"), false))
+	}
+	return names
+}
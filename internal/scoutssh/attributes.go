@@ -0,0 +1,120 @@
+package scoutssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// AttrChange lists the attributes to set; nil fields are left untouched.
+// In a recursive change Mode is set on directories only, while files get the
+// ModeAdded bits set and the ModeRemoved bits cleared, like chmod u+w.
+type AttrChange struct {
+	Mode        *os.FileMode
+	ModeAdded   os.FileMode
+	ModeRemoved os.FileMode
+	UID         *uint32
+	GID         *uint32
+	ModTime     *time.Time
+}
+
+// SetAttributes applies change to root and, when recursive, to everything below it.
+// Links below root are skipped because SFTP would change their targets instead.
+func SetAttributes(client *sftp.Client, root string, change AttrChange, recursive bool) error {
+	root = strings.TrimSuffix(root, "/")
+	if !recursive {
+		info, err := client.Stat(root)
+		if err != nil {
+			return err
+		}
+		return setAttributes(client, root, info, change, false)
+	}
+
+	var errs []error
+	walker := client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", walker.Path(), err))
+			continue
+		}
+		if walker.Stat().Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		if err := setAttributes(client, walker.Path(), walker.Stat(), change, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", walker.Path(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setAttributes(client *sftp.Client, target string, info os.FileInfo, change AttrChange, recursive bool) error {
+	if change.Mode != nil {
+		mode := *change.Mode
+		if recursive && !info.IsDir() {
+			current := FileModeFromOctal(OctalFromFileMode(info.Mode()))
+			mode = (current | change.ModeAdded) &^ change.ModeRemoved
+		}
+		if err := client.Chmod(target, mode); err != nil {
+			return fmt.Errorf("chmod: %v", err)
+		}
+	}
+	if change.UID != nil || change.GID != nil {
+		var uid, gid uint32
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			uid, gid = stat.UID, stat.GID
+		}
+		if change.UID != nil {
+			uid = *change.UID
+		}
+		if change.GID != nil {
+			gid = *change.GID
+		}
+		if err := client.Chown(target, int(uid), int(gid)); err != nil {
+			return fmt.Errorf("chown: %v", err)
+		}
+	}
+	if change.ModTime != nil {
+		atime := *change.ModTime
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			atime = time.Unix(int64(stat.Atime), 0)
+		}
+		if err := client.Chtimes(target, atime, *change.ModTime); err != nil {
+			return fmt.Errorf("touch: %v", err)
+		}
+	}
+	return nil
+}
+
+// FileModeFromOctal converts a chmod-style value such as 04755 to an os.FileMode.
+func FileModeFromOctal(value uint32) os.FileMode {
+	mode := os.FileMode(value & 0o777)
+	if value&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if value&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if value&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// OctalFromFileMode is the inverse of FileModeFromOctal.
+func OctalFromFileMode(mode os.FileMode) uint32 {
+	value := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		value |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		value |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		value |= 0o1000
+	}
+	return value
+}
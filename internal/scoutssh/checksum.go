@@ -0,0 +1,93 @@
+package scoutssh
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+type HashAlgorithm int
+
+const (
+	SHA256 HashAlgorithm = iota
+	SHA1
+	MD5
+)
+
+var HashAlgorithms = []HashAlgorithm{SHA256, SHA1, MD5}
+
+func (a HashAlgorithm) String() string {
+	switch a {
+	case SHA1:
+		return "SHA-1"
+	case MD5:
+		return "MD5"
+	}
+	return "SHA-256"
+}
+
+func (a HashAlgorithm) command() string {
+	switch a {
+	case SHA1:
+		return "sha1sum"
+	case MD5:
+		return "md5sum"
+	}
+	return "sha256sum"
+}
+
+func (a HashAlgorithm) new() hash.Hash {
+	switch a {
+	case SHA1:
+		return sha1.New()
+	case MD5:
+		return md5.New()
+	}
+	return sha256.New()
+}
+
+// Checksum returns the hex digest of a remote file.
+func Checksum(client *sftp.Client, sshClient *ssh.Client, remotePath string, algorithm HashAlgorithm) (string, error) {
+	output, err := RunCommand(sshClient, algorithm.command()+" -- "+ShellQuote(remotePath))
+	if err == nil {
+		if sum, _, ok := strings.Cut(strings.TrimSpace(output), " "); ok {
+			return strings.TrimPrefix(strings.ToLower(sum), "\\"), nil
+		}
+	} else if !errors.Is(err, errNoCommand) {
+		return "", err
+	}
+
+	file, err := client.Open(remotePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer file.Close()
+	return hashReader(file, algorithm)
+}
+
+func LocalChecksum(localPath string, algorithm HashAlgorithm) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open local file: %v", err)
+	}
+	defer file.Close()
+	return hashReader(file, algorithm)
+}
+
+func hashReader(r io.Reader, algorithm HashAlgorithm) (string, error) {
+	h := algorithm.new()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
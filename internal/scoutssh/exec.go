@@ -0,0 +1,158 @@
+package scoutssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// errNoCommand means the remote shell could not run the command at all. The
+// helpers in this package then do the same work through SFTP instead.
+var errNoCommand = errors.New("remote command unavailable")
+
+// missingCommandMessages are what shells print on stderr when a command is
+// missing or forbidden, for shells that exit with 1 or 2 instead of 127.
+var missingCommandMessages = []string{
+	"command not found",
+	": not found",
+	"is not recognized",
+	": restricted",
+}
+
+// RunCommand runs cmd in a new session and returns its stdout, or errNoCommand
+// when the host cannot run it.
+func RunCommand(client *ssh.Client, cmd string) (string, error) {
+	var stdout bytes.Buffer
+	err := StreamCommand(client, cmd, &stdout)
+	return stdout.String(), err
+}
+
+// StreamCommand runs cmd in a new session and copies its stdout to w as it arrives.
+func StreamCommand(client *ssh.Client, cmd string, w io.Writer) error {
+	return streamCommandContext(context.Background(), client, cmd, w)
+}
+
+// streamCommandContext is StreamCommand that closes the session when ctx is done.
+func streamCommandContext(ctx context.Context, client *ssh.Client, cmd string, w io.Writer) error {
+	if client == nil {
+		return errNoCommand
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return errNoCommand
+	}
+	defer session.Close()
+	stop := context.AfterFunc(ctx, func() {
+		session.Close()
+	})
+	defer stop()
+
+	var stderr bytes.Buffer
+	session.Stdout = w
+	session.Stderr = &stderr
+	if err := session.Run(cmd); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		message := strings.TrimSpace(stderr.String())
+		var exitErr *ssh.ExitError
+		if !errors.As(err, &exitErr) {
+			// The server refused exec, as SFTP-only accounts do, or never
+			// reported an exit status.
+			return errNoCommand
+		}
+		if missingCommand(exitErr.ExitStatus(), message) {
+			return errNoCommand
+		}
+		if message != "" {
+			return errors.New(message)
+		}
+		return err
+	}
+	return nil
+}
+
+// missingCommand reports whether a command exiting with status and stderr message never ran.
+func missingCommand(status int, message string) bool {
+	switch status {
+	case 126, 127:
+		return true
+	case 1, 2:
+		for _, missing := range missingCommandMessages {
+			if strings.Contains(message, missing) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ShellQuote quotes s for a POSIX shell.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// CopyRemote copies src to dst on the remote host with `cp -a`.
+func CopyRemote(client *sftp.Client, sshClient *ssh.Client, src, dst string) error {
+	src, dst = strings.TrimSuffix(src, "/"), strings.TrimSuffix(dst, "/")
+	if _, err := client.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	_, err := RunCommand(sshClient, "cp -a -- "+ShellQuote(src)+" "+ShellQuote(dst))
+	if !errors.Is(err, errNoCommand) {
+		return err
+	}
+
+	walker := client.Walk(src)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return fmt.Errorf("failed to read directory: %v", err)
+		}
+		target := path.Join(dst, strings.TrimPrefix(walker.Path(), src))
+		if err := copyEntry(client, walker.Path(), target, walker.Stat()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyEntry(client *sftp.Client, src, dst string, info os.FileInfo) error {
+	switch {
+	case info.IsDir():
+		if err := client.Mkdir(dst); err != nil {
+			return fmt.Errorf("failed to create directory: %v", err)
+		}
+		return client.Chmod(dst, info.Mode().Perm())
+	case info.Mode()&os.ModeSymlink != 0:
+		linkTarget, err := client.ReadLink(src)
+		if err != nil {
+			return fmt.Errorf("failed to read link: %v", err)
+		}
+		return client.Symlink(linkTarget, dst)
+	}
+
+	srcFile, err := client.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer srcFile.Close()
+	dstFile, err := client.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file: %v", err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("failed to copy %s: %v", path.Base(src), err)
+	}
+	return client.Chmod(dst, info.Mode().Perm())
+}
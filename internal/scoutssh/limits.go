@@ -0,0 +1,143 @@
+package scoutssh
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP packet types used to ask the server for its limits.
+const (
+	fxpInit          = 1
+	fxpVersion       = 2
+	fxpExtended      = 200
+	fxpExtendedReply = 201
+)
+
+const limitsExtension = "limits@openssh.com"
+
+// maxUncheckedPacket is the largest payload pkg/sftp can receive, whose packets
+// are capped at 256 KiB, with room left for the packet header.
+const maxUncheckedPacket = 256<<10 - 1024
+
+// serverPacketLimit returns the largest read and write the server accepts, or
+// 0 when it does not advertise limits@openssh.com.
+func serverPacketLimit(sshClient *ssh.Client) (int, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return 0, err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return 0, err
+	}
+	return queryPacketLimit(r, w)
+}
+
+func queryPacketLimit(r io.Reader, w io.Writer) (int, error) {
+	if err := writePacket(w, fxpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		return 0, err
+	}
+	kind, payload, err := readPacket(r)
+	if err != nil {
+		return 0, err
+	}
+	if kind != fxpVersion || len(payload) < 4 {
+		return 0, fmt.Errorf("unexpected SFTP packet %d during init", kind)
+	}
+	if !hasExtension(payload[4:], limitsExtension) {
+		return 0, nil
+	}
+
+	request := binary.BigEndian.AppendUint32(nil, 1)
+	request = appendString(request, limitsExtension)
+	if err := writePacket(w, fxpExtended, request); err != nil {
+		return 0, err
+	}
+	kind, payload, err = readPacket(r)
+	if err != nil {
+		return 0, err
+	}
+	// The reply is the request id, then max packet, read, write length and open handles.
+	if kind != fxpExtendedReply || len(payload) < 4+4*8 {
+		return 0, nil
+	}
+	limit := uint64(maxUncheckedPacket)
+	for _, field := range []uint64{binary.BigEndian.Uint64(payload[12:]), binary.BigEndian.Uint64(payload[20:])} {
+		// Zero means the server sets no limit.
+		if field != 0 {
+			limit = min(limit, field)
+		}
+	}
+	return int(limit), nil
+}
+
+// hasExtension reports whether the extension pairs of a version packet name extension.
+func hasExtension(data []byte, extension string) bool {
+	for len(data) > 0 {
+		name, rest, ok := cutString(data)
+		if !ok {
+			return false
+		}
+		_, rest, ok = cutString(rest)
+		if !ok {
+			return false
+		}
+		if name == extension {
+			return true
+		}
+		data = rest
+	}
+	return false
+}
+
+func cutString(data []byte) (string, []byte, bool) {
+	if len(data) < 4 {
+		return "", nil, false
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(n) > uint64(len(data)-4) {
+		return "", nil, false
+	}
+	return string(data[4 : 4+n]), data[4+n:], true
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func writePacket(w io.Writer, kind byte, payload []byte) error {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, kind)
+	_, err := w.Write(append(packet, payload...))
+	return err
+}
+
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > 256<<10 {
+		return 0, nil, errors.New("invalid SFTP packet length")
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return 0, nil, err
+	}
+	return packet[0], packet[1:], nil
+}
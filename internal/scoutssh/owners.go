@@ -0,0 +1,86 @@
+package scoutssh
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// Owners maps numeric uid/gid values of a remote host to their names.
+type Owners struct {
+	users  map[uint32]string
+	groups map[uint32]string
+}
+
+// LookupOwners reads /etc/passwd and /etc/group of the remote host. Callers keep
+// the result for the lifetime of their connection. Hosts without readable account
+// databases fall back to numeric ids.
+func LookupOwners(client *sftp.Client) *Owners {
+	return &Owners{
+		users:  readAccountFile(client, "/etc/passwd"),
+		groups: readAccountFile(client, "/etc/group"),
+	}
+}
+
+func (o *Owners) User(uid uint32) string {
+	if name, ok := o.users[uid]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(uid), 10)
+}
+
+func (o *Owners) Group(gid uint32) string {
+	if name, ok := o.groups[gid]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(gid), 10)
+}
+
+// UserID resolves a user name or numeric uid.
+func (o *Owners) UserID(name string) (uint32, bool) {
+	return lookupID(o.users, name)
+}
+
+// GroupID resolves a group name or numeric gid.
+func (o *Owners) GroupID(name string) (uint32, bool) {
+	return lookupID(o.groups, name)
+}
+
+func lookupID(names map[uint32]string, name string) (uint32, bool) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(id), true
+	}
+	for id, known := range names {
+		if known == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func readAccountFile(client *sftp.Client, path string) map[uint32]string {
+	names := make(map[uint32]string)
+	file, err := client.Open(path)
+	if err != nil {
+		return names
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		id, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			continue
+		}
+		if _, exists := names[uint32(id)]; !exists {
+			names[uint32(id)] = fields[0]
+		}
+	}
+	return names
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net"
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	"os"
 	"os/user"
@@ -24,6 +26,13 @@ import (
 
 var LocalHome, RemoteHome string
 
+// MaxPacket is the SFTP payload size requested per read or write. Values above
+// maxCheckedPacket, the largest size every server must accept, are only used up
+// to the limit a server advertises through limits@openssh.com.
+var MaxPacket = 1 << 15
+
+const maxCheckedPacket = 1 << 15
+
 func init() {
 	var err error
 	LocalHome, err = os.UserHomeDir()
@@ -209,7 +218,7 @@ func Connect(w fyne.Window, host string) (*sftp.Client, *ssh.Client, error) {
 		}
 		sshClient := ssh.NewClient(ncc, chans, reqs)
 
-		sftpClient, err := sftp.NewClient(sshClient)
+		sftpClient, err := newSFTPClient(sshClient)
 		if err != nil {
 			sshClient.Close()
 			return nil, nil, err
@@ -240,7 +249,7 @@ func Connect(w fyne.Window, host string) (*sftp.Client, *ssh.Client, error) {
 		}
 	}
 
-	sftpClient, err := sftp.NewClient(sshClient)
+	sftpClient, err := newSFTPClient(sshClient)
 	if err != nil {
 		sshClient.Close()
 		return nil, nil, err
@@ -249,6 +258,24 @@ func Connect(w fyne.Window, host string) (*sftp.Client, *ssh.Client, error) {
 	return sftpClient, sshClient, nil
 }
 
+// newSFTPClient opens an SFTP session that pipelines reads and writes of a file,
+// which is what keeps throughput up on high-latency links.
+func newSFTPClient(sshClient *ssh.Client) (*sftp.Client, error) {
+	packet := min(MaxPacket, maxCheckedPacket)
+	if MaxPacket > maxCheckedPacket {
+		limit, err := serverPacketLimit(sshClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query SFTP limits: %v", err)
+		}
+		packet = max(packet, min(MaxPacket, limit))
+	}
+	return sftp.NewClient(sshClient,
+		sftp.MaxPacketUnchecked(packet),
+		sftp.UseConcurrentReads(true),
+		sftp.UseConcurrentWrites(true),
+	)
+}
+
 func RequestPassword(host, hostname string, w fyne.Window) string {
 
 	passwordChan := make(chan string)
@@ -268,38 +295,64 @@ func RequestPassword(host, hostname string, w fyne.Window) string {
 }
 
 type FileInfo struct {
-	Name     string
-	IsDir    bool
-	IsLink   bool
-	FullPath string
+	Name       string
+	IsDir      bool
+	IsLink     bool
+	FullPath   string
+	Size       int64
+	Mode       os.FileMode
+	UID        uint32
+	GID        uint32
+	Owner      string
+	Group      string
+	ModTime    time.Time
+	LinkTarget string
 }
 
+// RemoveSFTP removes path recursively without following links and returns its parent directory.
+// It keeps going past entries it cannot remove and reports all of them in the returned error.
 func RemoveSFTP(client *sftp.Client, path string) (string, error) {
-	info, err := client.Stat(path)
-	if err != nil {
-		return "", err
+	path = strings.TrimSuffix(path, "/")
+	parent := filepath.Dir(path)
+	if parent != "/" {
+		parent += "/"
 	}
+	return parent, removeTree(client, path)
+}
 
-	if info.IsDir() {
-		entries, err := client.ReadDir(path)
-		if err != nil {
-			return "", err
+func removeTree(client *sftp.Client, path string) error {
+	info, err := client.Lstat(path)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if !info.IsDir() {
+		if err := client.Remove(path); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
 		}
+		return nil
+	}
 
-		for _, entry := range entries {
-			_, err = RemoveSFTP(client, path+"/"+entry.Name())
-			if err != nil {
-				return "", err
-			}
+	entries, err := client.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		if err := removeTree(client, path+"/"+entry.Name()); err != nil {
+			errs = append(errs, err)
 		}
-
-		return filepath.Dir(filepath.Dir(path)) + "/", client.RemoveDirectory(path)
 	}
-
-	return filepath.Dir(path) + "/", client.Remove(path)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if err := client.RemoveDirectory(path); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
 }
 
-func FetchSFTPData(client *sftp.Client, path string) (map[string][]FileInfo, error) {
+// FetchSFTPData lists path, naming entry owners with owners.
+func FetchSFTPData(client *sftp.Client, owners *Owners, path string) (map[string][]FileInfo, error) {
 	data := make(map[string][]FileInfo)
 	entries, err := client.ReadDir(path)
 	if err != nil {
@@ -317,10 +370,12 @@ func FetchSFTPData(client *sftp.Client, path string) (map[string][]FileInfo, err
 			isLink := entry.Mode()&os.ModeSymlink != 0
 			isDir := entry.IsDir()
 			name := entry.Name()
+			linkTarget := ""
 
 			if isLink {
 				name += "*"
 				if realPath, err := client.ReadLink(fullPath); err == nil {
+					linkTarget = realPath
 					if linkInfo, err := client.Stat(fullPath); err == nil && linkInfo.IsDir() {
 						fullPath = "/" + realPath + "/"
 						isDir = true
@@ -331,10 +386,20 @@ func FetchSFTPData(client *sftp.Client, path string) (map[string][]FileInfo, err
 			}
 
 			fileInfo := FileInfo{
-				Name:     name,
-				FullPath: fullPath,
-				IsDir:    isDir,
-				IsLink:   isLink,
+				Name:       name,
+				FullPath:   fullPath,
+				IsDir:      isDir,
+				IsLink:     isLink,
+				Size:       entry.Size(),
+				Mode:       entry.Mode(),
+				ModTime:    entry.ModTime(),
+				LinkTarget: linkTarget,
+			}
+			if stat, ok := entry.Sys().(*sftp.FileStat); ok {
+				fileInfo.UID = stat.UID
+				fileInfo.GID = stat.GID
+				fileInfo.Owner = owners.User(stat.UID)
+				fileInfo.Group = owners.Group(stat.GID)
 			}
 
 			fileInfoChan <- fileInfo
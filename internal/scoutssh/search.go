@@ -0,0 +1,190 @@
+package scoutssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// maxSearchLine caps how long a single line of a searched file may be.
+const maxSearchLine = 1 << 20
+
+// maxErrorLines caps how many lines of remote stderr a search error shows.
+const maxErrorLines = 10
+
+type SearchOptions struct {
+	// Name is a glob matched against base names; empty matches every file.
+	Name string
+	// Content is a literal string searched for inside files; empty searches names only.
+	Content    string
+	IgnoreCase bool
+}
+
+// SearchMatch is a matching file, or a matching line when Line is not zero.
+type SearchMatch struct {
+	Path string
+	Line int
+	Text string
+}
+
+// Search passes files below root that match options to found as they arrive.
+func Search(ctx context.Context, client *sftp.Client, sshClient *ssh.Client, root string, options SearchOptions, found func(SearchMatch)) error {
+	root = strings.TrimSuffix(root, "/")
+	if root == "" {
+		root = "/"
+	}
+
+	reader, writer := io.Pipe()
+	parsed := make(chan struct{})
+	go func() {
+		defer close(parsed)
+		parseSearchOutput(reader, options.Content != "", found)
+	}()
+	err := streamCommandContext(ctx, sshClient, searchCommand(root, options), writer)
+	writer.Close()
+	<-parsed
+	if !errors.Is(err, errNoCommand) {
+		if err != nil && ctx.Err() == nil {
+			return shortenError(err)
+		}
+		return err
+	}
+	return walkSearch(ctx, client, root, options, found)
+}
+
+func searchCommand(root string, options SearchOptions) string {
+	if options.Content == "" {
+		nameFlag := "-name"
+		if options.IgnoreCase {
+			nameFlag = "-iname"
+		}
+		cmd := "command -v find >/dev/null || exit 127; find " + ShellQuote(root) + " -type f"
+		if options.Name != "" {
+			cmd += " " + nameFlag + " " + ShellQuote(options.Name)
+		}
+		return cmd
+	}
+
+	flags := "-rnIFZ"
+	if options.IgnoreCase {
+		flags += "i"
+	}
+	cmd := "command -v grep >/dev/null || exit 127; grep " + flags
+	if options.Name != "" {
+		cmd += " --include=" + ShellQuote(options.Name)
+	}
+	// grep exits with 1 when nothing matched, which is not an error here.
+	return cmd + " -e " + ShellQuote(options.Content) + " -- " + ShellQuote(root) + "; s=$?; [ $s -le 1 ] || exit $s"
+}
+
+// shortenError keeps the first lines of a multi-line remote error, such as one
+// "Permission denied" per unreadable directory.
+func shortenError(err error) error {
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) <= maxErrorLines {
+		return err
+	}
+	return fmt.Errorf("%s\n... and %d more", strings.Join(lines[:maxErrorLines], "\n"), len(lines)-maxErrorLines)
+}
+
+// parseSearchOutput reads find paths, or grep -Z lines in the form path\0line:text.
+func parseSearchOutput(r io.Reader, content bool, found func(SearchMatch)) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxSearchLine)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !content {
+			found(SearchMatch{Path: line})
+			continue
+		}
+		file, rest, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		number, text, _ := strings.Cut(rest, ":")
+		lineNumber, err := strconv.Atoi(number)
+		if err != nil {
+			continue
+		}
+		found(SearchMatch{Path: file, Line: lineNumber, Text: text})
+	}
+	io.Copy(io.Discard, r)
+}
+
+func walkSearch(ctx context.Context, client *sftp.Client, root string, options SearchOptions, found func(SearchMatch)) error {
+	walker := client.Walk(root)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walker.Err() != nil || !walker.Stat().Mode().IsRegular() {
+			continue
+		}
+		if !matchName(options, path.Base(walker.Path())) {
+			continue
+		}
+		if options.Content == "" {
+			found(SearchMatch{Path: walker.Path()})
+			continue
+		}
+		if err := grepFile(ctx, client, walker.Path(), options, found); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func matchName(options SearchOptions, name string) bool {
+	if options.Name == "" {
+		return true
+	}
+	pattern := options.Name
+	if options.IgnoreCase {
+		pattern, name = strings.ToLower(pattern), strings.ToLower(name)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+func grepFile(ctx context.Context, client *sftp.Client, filePath string, options SearchOptions, found func(SearchMatch)) error {
+	file, err := client.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer file.Close()
+
+	needle := []byte(options.Content)
+	if options.IgnoreCase {
+		needle = bytes.ToLower(needle)
+	}
+
+	reader := bufio.NewReader(file)
+	if head, _ := reader.Peek(8000); bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxSearchLine)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := scanner.Bytes()
+		haystack := line
+		if options.IgnoreCase {
+			haystack = bytes.ToLower(line)
+		}
+		if bytes.Contains(haystack, needle) {
+			found(SearchMatch{Path: filePath, Line: lineNumber, Text: string(line)})
+		}
+	}
+	return scanner.Err()
+}
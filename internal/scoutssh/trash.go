@@ -0,0 +1,144 @@
+package scoutssh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// originSuffix marks the file next to a trashed entry that records where it came from.
+const originSuffix = ".origin"
+
+// TreeStats counts the entries under a path, links included but not followed.
+type TreeStats struct {
+	Files int
+	Dirs  int
+	Size  int64
+}
+
+func (s *TreeStats) Add(other TreeStats) {
+	s.Files += other.Files
+	s.Dirs += other.Dirs
+	s.Size += other.Size
+}
+
+func CountTree(client *sftp.Client, root string) (TreeStats, error) {
+	var stats TreeStats
+	walker := client.Walk(strings.TrimSuffix(root, "/"))
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return stats, fmt.Errorf("failed to read directory: %v", err)
+		}
+		if walker.Stat().IsDir() {
+			stats.Dirs++
+		} else {
+			stats.Files++
+			stats.Size += walker.Stat().Size()
+		}
+	}
+	return stats, nil
+}
+
+// TrashEntry is an entry moved into a trash directory by MoveToTrash.
+type TrashEntry struct {
+	Name    string
+	Path    string
+	Origin  string
+	Deleted time.Time
+	IsDir   bool
+}
+
+// MoveToTrash renames target into trashDir under a unique name and records its origin.
+func MoveToTrash(client *sftp.Client, trashDir, target string) (TrashEntry, error) {
+	target = strings.TrimSuffix(target, "/")
+	if err := client.MkdirAll(trashDir); err != nil {
+		return TrashEntry{}, fmt.Errorf("failed to create trash directory: %v", err)
+	}
+
+	deleted := time.Now()
+	name := strconv.FormatInt(deleted.UnixNano(), 10) + "-" + path.Base(target)
+	entry := TrashEntry{Name: name, Path: path.Join(trashDir, name), Origin: target, Deleted: deleted}
+
+	origin, err := client.Create(entry.Path + originSuffix)
+	if err != nil {
+		return TrashEntry{}, fmt.Errorf("failed to write trash record: %v", err)
+	}
+	_, err = io.WriteString(origin, target)
+	origin.Close()
+	if err == nil {
+		err = client.Rename(target, entry.Path)
+	}
+	if err != nil {
+		client.Remove(entry.Path + originSuffix)
+		return TrashEntry{}, fmt.Errorf("failed to move to trash: %v", err)
+	}
+	return entry, nil
+}
+
+// ListTrash returns the trashed entries, newest first.
+func ListTrash(client *sftp.Client, trashDir string) ([]TrashEntry, error) {
+	infos, err := client.ReadDir(trashDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash directory: %v", err)
+	}
+
+	var entries []TrashEntry
+	for _, info := range infos {
+		if strings.HasSuffix(info.Name(), originSuffix) {
+			continue
+		}
+		entry := TrashEntry{Name: info.Name(), Path: path.Join(trashDir, info.Name()), IsDir: info.IsDir()}
+		if stamp, _, ok := strings.Cut(info.Name(), "-"); ok {
+			if nanos, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+				entry.Deleted = time.Unix(0, nanos)
+			}
+		}
+		if origin, err := client.Open(entry.Path + originSuffix); err == nil {
+			data, _ := io.ReadAll(origin)
+			origin.Close()
+			entry.Origin = string(data)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Deleted.After(entries[j].Deleted)
+	})
+	return entries, nil
+}
+
+// RestoreFromTrash moves an entry back to its origin unless something already exists there.
+func RestoreFromTrash(client *sftp.Client, entry TrashEntry) error {
+	if entry.Origin == "" {
+		return fmt.Errorf("%s: original location unknown", entry.Name)
+	}
+	if _, err := client.Lstat(entry.Origin); err == nil {
+		return fmt.Errorf("%s already exists", entry.Origin)
+	}
+	if err := client.MkdirAll(path.Dir(entry.Origin)); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+	if err := client.Rename(entry.Path, entry.Origin); err != nil {
+		return fmt.Errorf("failed to restore %s: %v", entry.Origin, err)
+	}
+	client.Remove(entry.Path + originSuffix)
+	return nil
+}
+
+// PurgeTrash deletes a trashed entry for good.
+func PurgeTrash(client *sftp.Client, entry TrashEntry) error {
+	if _, err := RemoveSFTP(client, entry.Path); err != nil {
+		return err
+	}
+	client.Remove(entry.Path + originSuffix)
+	return nil
+}
@@ -0,0 +1,187 @@
+package scoutssh
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// usageWorkers bounds how many directories are listed at once during a walk.
+const usageWorkers = 10
+
+// UsageNode is the size of a file or of everything below a directory.
+type UsageNode struct {
+	Name     string
+	Path     string
+	Size     int64
+	Files    int
+	IsDir    bool
+	Children []*UsageNode
+	Parent   *UsageNode
+}
+
+// DiskUsage returns the size tree of root; scanned is called with the number of entries seen so far.
+func DiskUsage(ctx context.Context, client *sftp.Client, sshClient *ssh.Client, root string, scanned func(int64)) (*UsageNode, error) {
+	root = strings.TrimSuffix(root, "/")
+	if root == "" {
+		root = "/"
+	}
+
+	reader, writer := io.Pipe()
+	parsed := make(chan *UsageNode)
+	go func() {
+		parsed <- parseDu(reader, root, scanned)
+	}()
+	// `du -a` prints files and directories alike, so the empty directories, the
+	// only ones without children to tell them apart, follow after a blank line.
+	quoted := ShellQuote(root)
+	cmd := "command -v du >/dev/null || exit 127; du -ak " + quoted + " 2>/dev/null; echo; find " + quoted + " -type d -empty 2>/dev/null; exit 0"
+	err := streamCommandContext(ctx, sshClient, cmd, writer)
+	writer.Close()
+	node := <-parsed
+	if !errors.Is(err, errNoCommand) {
+		if err != nil {
+			return nil, err
+		}
+		node.total()
+		return node, nil
+	}
+
+	node, err = walkUsage(ctx, client, root, scanned)
+	if err != nil {
+		return nil, err
+	}
+	node.total()
+	return node, nil
+}
+
+// parseDu builds the tree from `du -ak` lines in the form "kilobytes<TAB>path",
+// followed by a blank line and the paths of empty directories.
+func parseDu(r io.Reader, root string, scanned func(int64)) *UsageNode {
+	rootNode := &UsageNode{Name: root, Path: root, IsDir: true}
+	nodes := map[string]*UsageNode{root: rootNode}
+
+	var ensure func(p string) *UsageNode
+	ensure = func(p string) *UsageNode {
+		if node, ok := nodes[p]; ok {
+			return node
+		}
+		parent := ensure(path.Dir(p))
+		parent.IsDir = true
+		node := &UsageNode{Name: path.Base(p), Path: p, Parent: parent}
+		parent.Children = append(parent.Children, node)
+		nodes[p] = node
+		return node
+	}
+
+	var count int64
+	emptyDirs := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			emptyDirs = true
+			continue
+		}
+		if emptyDirs {
+			if node, ok := nodes[line]; ok {
+				node.IsDir = true
+			}
+			continue
+		}
+		size, entry, ok := strings.Cut(line, "\t")
+		if !ok || (entry != root && !strings.HasPrefix(entry, strings.TrimSuffix(root, "/")+"/")) {
+			continue
+		}
+		kilobytes, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			continue
+		}
+		ensure(entry).Size = kilobytes * 1024
+		if count++; scanned != nil && count%1000 == 0 {
+			scanned(count)
+		}
+	}
+	io.Copy(io.Discard, r)
+	return rootNode
+}
+
+func walkUsage(ctx context.Context, client *sftp.Client, root string, scanned func(int64)) (*UsageNode, error) {
+	rootNode := &UsageNode{Name: root, Path: root, IsDir: true}
+	slots := make(chan struct{}, usageWorkers)
+	var count atomic.Int64
+	var wg sync.WaitGroup
+
+	var visit func(node *UsageNode)
+	visit = func(node *UsageNode) {
+		defer wg.Done()
+		if ctx.Err() != nil {
+			return
+		}
+		slots <- struct{}{}
+		entries, err := client.ReadDir(node.Path)
+		<-slots
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			child := &UsageNode{Name: entry.Name(), Path: path.Join(node.Path, entry.Name()), Parent: node}
+			if entry.IsDir() {
+				child.IsDir = true
+				wg.Add(1)
+				go visit(child)
+			} else {
+				child.Size = entry.Size()
+			}
+			node.Children = append(node.Children, child)
+		}
+		if n := count.Add(int64(len(entries))); scanned != nil {
+			scanned(n)
+		}
+	}
+
+	wg.Add(1)
+	go visit(rootNode)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return rootNode, nil
+}
+
+// total fills in directory sizes and file counts bottom-up and orders children by size.
+// Sizes reported by du already include the children, so they are only summed when missing.
+func (n *UsageNode) total() {
+	if !n.IsDir {
+		n.Files = 1
+		return
+	}
+	var size int64
+	n.Files = 0
+	for _, child := range n.Children {
+		child.total()
+		size += child.Size
+		n.Files += child.Files
+	}
+	if n.Size < size {
+		n.Size = size
+	}
+	n.Sort(func(a, b *UsageNode) bool { return a.Size > b.Size })
+}
+
+// Sort orders the direct children of n.
+func (n *UsageNode) Sort(less func(a, b *UsageNode) bool) {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		return less(n.Children[i], n.Children[j])
+	})
+}
@@ -0,0 +1,164 @@
+// Package textdiff computes line diffs with the Myers algorithm.
+package textdiff
+
+import "strings"
+
+// maxEdits bounds the work spent on very different inputs; past it the
+// differing middle is reported as one replaced block.
+const maxEdits = 2000
+
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Line is one line of a diff. Left and Right are 1-based line numbers in the
+// old and new text, zero for the side the line is missing from.
+type Line struct {
+	Op    Op
+	Text  string
+	Left  int
+	Right int
+}
+
+// SplitLines splits text into lines without their terminators.
+func SplitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Lines returns the diff of a and b as a sequence of equal, deleted and inserted lines.
+func Lines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: Equal, Text: a[i], Left: i + 1, Right: i + 1})
+	}
+	for _, line := range middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if line.Left > 0 {
+			line.Left += prefix
+		}
+		if line.Right > 0 {
+			line.Right += prefix
+		}
+		lines = append(lines, line)
+	}
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, Line{Op: Equal, Text: a[len(a)-i], Left: len(a) - i + 1, Right: len(b) - i + 1})
+	}
+	return lines
+}
+
+// Hunks returns the indexes of lines where a run of changes starts.
+func Hunks(lines []Line) []int {
+	var hunks []int
+	for i, line := range lines {
+		if line.Op != Equal && (i == 0 || lines[i-1].Op == Equal) {
+			hunks = append(hunks, i)
+		}
+	}
+	return hunks
+}
+
+func middle(a, b []string) []Line {
+	trace, ok := shortestEdit(a, b)
+	if !ok {
+		return replaced(a, b)
+	}
+
+	var reversed []Line
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d-1]
+		get := func(k int) int { return int(previous[k+d-1]) }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			reversed = append(reversed, Line{Op: Equal, Text: a[x], Left: x + 1, Right: y + 1})
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Op: Insert, Text: b[prevY], Right: prevY + 1})
+		} else {
+			reversed = append(reversed, Line{Op: Delete, Text: a[prevX], Left: prevX + 1})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		reversed = append(reversed, Line{Op: Equal, Text: a[x], Left: x + 1, Right: y + 1})
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// shortestEdit runs the forward Myers search and keeps, for every edit distance d,
+// the furthest x reached on diagonals -d..d so the path can be traced back.
+func shortestEdit(a, b []string) ([][]int32, bool) {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+	offset := limit + 1
+	v := make([]int32, 2*limit+3)
+
+	var trace [][]int32
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = int(v[offset+k+1])
+			} else {
+				x = int(v[offset+k-1]) + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = int32(x)
+			if x >= n && y >= m {
+				trace = append(trace, append([]int32(nil), v[offset-d:offset+d+1]...))
+				return trace, true
+			}
+		}
+		trace = append(trace, append([]int32(nil), v[offset-d:offset+d+1]...))
+	}
+	return nil, false
+}
+
+func replaced(a, b []string) []Line {
+	var lines []Line
+	for i, text := range a {
+		lines = append(lines, Line{Op: Delete, Text: text, Left: i + 1})
+	}
+	for i, text := range b {
+		lines = append(lines, Line{Op: Insert, Text: text, Right: i + 1})
+	}
+	return lines
+}
@@ -0,0 +1,106 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Policy decides what happens to a job whose target already exists.
+type Policy int
+
+const (
+	Overwrite Policy = iota
+	Skip
+	Rename
+	OverwriteIfNewer
+)
+
+var Policies = []Policy{Overwrite, Skip, Rename, OverwriteIfNewer}
+
+func (p Policy) String() string {
+	switch p {
+	case Overwrite:
+		return "overwrite"
+	case Skip:
+		return "skip"
+	case Rename:
+		return "rename with suffix"
+	case OverwriteIfNewer:
+		return "overwrite if newer"
+	}
+	return "unknown"
+}
+
+// Conflicts lists the jobs that would replace an existing file, which doubles as a dry run.
+func (b *Batch) Conflicts() []*Job {
+	var conflicts []*Job
+	for _, j := range b.Jobs {
+		if j.existing != nil {
+			conflicts = append(conflicts, j)
+		}
+	}
+	return conflicts
+}
+
+// Resolve applies policy to every conflicting job of the batch.
+func (b *Batch) Resolve(policy Policy) {
+	for _, j := range b.Conflicts() {
+		b.ResolveJob(j, policy)
+	}
+}
+
+func (b *Batch) ResolveJob(j *Job, policy Policy) {
+	switch policy {
+	case Skip:
+		j.skip = true
+	case OverwriteIfNewer:
+		j.skip = !j.modTime.After(j.existing.ModTime())
+	case Rename:
+		j.Target = b.uniqueTarget(j.Target)
+	}
+	j.existing = nil
+}
+
+// Existing returns the file a job would replace, or nil.
+func (j *Job) Existing() os.FileInfo {
+	return j.existing
+}
+
+// ModTime returns the modification time of the job source.
+func (j *Job) ModTime() time.Time {
+	return j.modTime
+}
+
+func (b *Batch) lstat(target string) os.FileInfo {
+	var info os.FileInfo
+	var err error
+	if b.remote {
+		info, err = b.client.Lstat(target)
+	} else {
+		info, err = os.Lstat(target)
+	}
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// uniqueTarget appends " (n)" before the extension until the name is free.
+func (b *Batch) uniqueTarget(target string) string {
+	dir, name := path.Split(filepath.ToSlash(target))
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; ; n++ {
+		candidate := path.Join(dir, fmt.Sprintf("%s (%d)%s", base, n, ext))
+		if !b.remote {
+			candidate = filepath.FromSlash(candidate)
+		}
+		if b.lstat(candidate) == nil {
+			return candidate
+		}
+	}
+}
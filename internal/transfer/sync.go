@@ -0,0 +1,360 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+type SyncDirection int
+
+const (
+	LocalToRemote SyncDirection = iota
+	RemoteToLocal
+)
+
+type ChangeKind int
+
+const (
+	Add ChangeKind = iota
+	Update
+	Delete
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Add:
+		return "add"
+	case Update:
+		return "update"
+	case Delete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+type SyncOptions struct {
+	Direction SyncDirection
+	// Checksum compares file contents by sha256 instead of size and mtime.
+	Checksum bool
+	// Delete removes destination entries that are missing on the source side.
+	Delete  bool
+	Include []string
+	Exclude []string
+}
+
+// Change is one difference between the source and destination trees, by relative slash path.
+type Change struct {
+	Kind  ChangeKind
+	Path  string
+	Size  int64
+	IsDir bool
+
+	replace bool
+}
+
+// SyncPlan is the preview of a sync; nothing is changed until it is applied.
+type SyncPlan struct {
+	Changes []Change
+
+	client     *sftp.Client
+	localRoot  string
+	remoteRoot string
+	options    SyncOptions
+	source     map[string]os.FileInfo
+}
+
+// PlanSync compares a local folder with a remote directory.
+func (m *Manager) PlanSync(client *sftp.Client, localRoot, remoteRoot string, options SyncOptions) (*SyncPlan, error) {
+	// Only the destination may be missing; a missing source would plan to delete everything.
+	local, err := listLocal(localRoot, options, options.Direction == RemoteToLocal)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := listRemote(client, remoteRoot, options, options.Direction == LocalToRemote)
+	if err != nil {
+		return nil, err
+	}
+
+	source, target := local, remote
+	if options.Direction == RemoteToLocal {
+		source, target = remote, local
+	}
+
+	plan := &SyncPlan{
+		client:     client,
+		localRoot:  localRoot,
+		remoteRoot: remoteRoot,
+		options:    options,
+		source:     source,
+	}
+
+	for rel, info := range source {
+		existing, ok := target[rel]
+		switch {
+		case !ok:
+			plan.Changes = append(plan.Changes, Change{Kind: Add, Path: rel, Size: info.Size(), IsDir: info.IsDir()})
+		case info.IsDir() != existing.IsDir():
+			plan.Changes = append(plan.Changes, Change{Kind: Update, Path: rel, Size: info.Size(), IsDir: info.IsDir(), replace: true})
+		case !info.IsDir():
+			changed, err := plan.changed(rel, info, existing)
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				plan.Changes = append(plan.Changes, Change{Kind: Update, Path: rel, Size: info.Size()})
+			}
+		}
+	}
+
+	if options.Delete {
+		for rel, info := range target {
+			if _, ok := source[rel]; ok {
+				continue
+			}
+			plan.Changes = append(plan.Changes, Change{Kind: Delete, Path: rel, Size: info.Size(), IsDir: info.IsDir()})
+		}
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Path < plan.Changes[j].Path
+	})
+	plan.dropNestedDeletes()
+	return plan, nil
+}
+
+// ApplySync performs the deletions and queues the additions and updates through the transfer manager.
+func (m *Manager) ApplySync(plan *SyncPlan) (*Batch, error) {
+	remote := plan.options.Direction == LocalToRemote
+	batch := &Batch{remote: remote, client: plan.client, preserve: m.Preserve()}
+
+	var err error
+	if remote {
+		err = plan.client.MkdirAll(plan.remoteRoot)
+	} else {
+		err = os.MkdirAll(plan.localRoot, os.ModePerm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	for i := len(plan.Changes) - 1; i >= 0; i-- {
+		change := plan.Changes[i]
+		if change.Kind != Delete && !change.replace {
+			continue
+		}
+		if err := plan.remove(plan.targetPath(change.Path)); err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %v", change.Path, err)
+		}
+	}
+
+	for _, change := range plan.Changes {
+		if change.Kind == Delete {
+			continue
+		}
+		info := plan.source[change.Path]
+		sourcePath, targetPath := plan.sourcePath(change.Path), plan.targetPath(change.Path)
+		if info.IsDir() {
+			batch.dirs = append(batch.dirs, dirAttrs{path: targetPath, mode: info.Mode(), modTime: info.ModTime()})
+			continue
+		}
+
+		direction := Upload
+		if !remote {
+			direction = Download
+		}
+		job := m.newJob(plan.client, direction, sourcePath, targetPath, info)
+		if info.Mode()&os.ModeSymlink != 0 {
+			linkTarget, err := plan.readLink(sourcePath)
+			if err != nil {
+				return nil, err
+			}
+			job.linkTarget = linkTarget
+		}
+		batch.Jobs = append(batch.Jobs, job)
+	}
+
+	if err := m.Start(batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (p *SyncPlan) changed(rel string, source, target os.FileInfo) (bool, error) {
+	if source.Size() != target.Size() {
+		return true, nil
+	}
+	if !p.options.Checksum {
+		return source.ModTime().Unix() != target.ModTime().Unix(), nil
+	}
+
+	localFile, err := os.Open(filepath.Join(p.localRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		return false, fmt.Errorf("failed to open local file: %v", err)
+	}
+	defer localFile.Close()
+	remoteFile, err := p.client.Open(path.Join(p.remoteRoot, rel))
+	if err != nil {
+		return false, fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	return !samePrefix(localFile, remoteFile, source.Size()), nil
+}
+
+// dropNestedDeletes keeps only the topmost deleted directory of a removed subtree.
+func (p *SyncPlan) dropNestedDeletes() {
+	var changes []Change
+	var deletedDirs []string
+	for _, change := range p.Changes {
+		if change.Kind == Delete {
+			if underAny(change.Path, deletedDirs) {
+				continue
+			}
+			if change.IsDir {
+				deletedDirs = append(deletedDirs, change.Path)
+			}
+		}
+		changes = append(changes, change)
+	}
+	p.Changes = changes
+}
+
+func (p *SyncPlan) sourcePath(rel string) string {
+	if p.options.Direction == LocalToRemote {
+		return filepath.Join(p.localRoot, filepath.FromSlash(rel))
+	}
+	return path.Join(p.remoteRoot, rel)
+}
+
+func (p *SyncPlan) targetPath(rel string) string {
+	if p.options.Direction == LocalToRemote {
+		return path.Join(p.remoteRoot, rel)
+	}
+	return filepath.Join(p.localRoot, filepath.FromSlash(rel))
+}
+
+func (p *SyncPlan) remove(target string) error {
+	if p.options.Direction == LocalToRemote {
+		return p.client.RemoveAll(target)
+	}
+	return os.RemoveAll(target)
+}
+
+func (p *SyncPlan) readLink(source string) (string, error) {
+	if p.options.Direction == LocalToRemote {
+		linkTarget, err := os.Readlink(source)
+		return filepath.ToSlash(linkTarget), err
+	}
+	return p.client.ReadLink(source)
+}
+
+// listLocal lists root recursively; a missing root is empty when missingOK is set.
+func listLocal(root string, options SyncOptions, missingOK bool) (map[string]os.FileInfo, error) {
+	entries := make(map[string]os.FileInfo)
+	info, err := os.Stat(root)
+	switch {
+	case os.IsNotExist(err) && missingOK:
+		return entries, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read local directory: %v", err)
+	case !info.IsDir():
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	err = filepath.Walk(root, func(entry string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read local directory: %v", err)
+		}
+		rel, err := filepath.Rel(root, entry)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !options.match(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		entries[rel] = info
+		return nil
+	})
+	return entries, err
+}
+
+// listRemote lists root recursively; a missing root is empty when missingOK is set.
+func listRemote(client *sftp.Client, root string, options SyncOptions, missingOK bool) (map[string]os.FileInfo, error) {
+	entries := make(map[string]os.FileInfo)
+	info, err := client.Stat(root)
+	switch {
+	case os.IsNotExist(err) && missingOK:
+		return entries, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read remote directory: %v", err)
+	case !info.IsDir():
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	walker := client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read remote directory: %v", err)
+		}
+		rel := relativeTo(walker.Path(), root)
+		if rel == "" {
+			continue
+		}
+		if !options.match(rel, walker.Stat().IsDir()) {
+			if walker.Stat().IsDir() {
+				walker.SkipDir()
+			}
+			continue
+		}
+		entries[rel] = walker.Stat()
+	}
+	return entries, nil
+}
+
+func relativeTo(entry, root string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(entry, strings.TrimSuffix(root, "/")), "/")
+}
+
+// match applies the exclude globs to every entry and the include globs to files only,
+// so that included files inside unlisted directories are still found.
+func (o SyncOptions) match(rel string, isDir bool) bool {
+	for _, pattern := range o.Exclude {
+		if globMatch(pattern, rel) {
+			return false
+		}
+	}
+	if isDir || len(o.Include) == 0 {
+		return true
+	}
+	for _, pattern := range o.Include {
+		if globMatch(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func globMatch(pattern, rel string) bool {
+	if matched, _ := path.Match(pattern, rel); matched {
+		return true
+	}
+	matched, _ := path.Match(pattern, path.Base(rel))
+	return matched
+}
+
+func underAny(rel string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,784 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// partSuffix marks files that are still being written; an interrupted transfer resumes from them.
+const partSuffix = ".part"
+
+type Direction int
+
+const (
+	Download Direction = iota
+	Upload
+)
+
+type State int
+
+const (
+	Queued State = iota
+	Running
+	Paused
+	Done
+	Failed
+	Canceled
+)
+
+func (s State) String() string {
+	switch s {
+	case Queued:
+		return "queued"
+	case Running:
+		return "running"
+	case Paused:
+		return "paused"
+	case Done:
+		return "done"
+	case Failed:
+		return "failed"
+	case Canceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// Job is a single file copied between the local machine and a remote host.
+type Job struct {
+	ID        int
+	Direction Direction
+	Source    string
+	Target    string
+	Size      int64
+
+	mode        os.FileMode
+	modTime     time.Time
+	linkTarget  string
+	preserve    bool
+	existing    os.FileInfo
+	skip        bool
+	client      *sftp.Client
+	manager     *Manager
+	transferred atomic.Int64
+	resumed     int64
+	state       State
+	err         error
+	started     time.Time
+	finished    time.Time
+	ctx         context.Context
+	cancel      context.CancelFunc
+	resume      chan struct{}
+	done        chan struct{}
+}
+
+// Batch groups the jobs created by one upload or download request.
+type Batch struct {
+	Jobs []*Job
+
+	remote   bool
+	preserve bool
+	client   *sftp.Client
+	dirs     []dirAttrs
+	done     chan struct{}
+	err      error
+}
+
+type dirAttrs struct {
+	path    string
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// Manager runs queued jobs with a bounded number of concurrent transfers.
+type Manager struct {
+	OnChange func()
+
+	mu          sync.Mutex
+	jobs        []*Job
+	nextID      int
+	running     int
+	concurrency int
+	verify      bool
+	preserve    bool
+}
+
+func NewManager(concurrency int) *Manager {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Manager{concurrency: concurrency, preserve: true}
+}
+
+func (m *Manager) Concurrency() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.concurrency
+}
+
+func (m *Manager) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	m.mu.Lock()
+	m.concurrency = n
+	m.mu.Unlock()
+	m.schedule()
+}
+
+// VerifyResume reports whether partial files are compared by checksum before a transfer is resumed.
+func (m *Manager) VerifyResume() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.verify
+}
+
+func (m *Manager) SetVerifyResume(verify bool) {
+	m.mu.Lock()
+	m.verify = verify
+	m.mu.Unlock()
+}
+
+// Preserve reports whether modes, mtimes and symlinks are kept like `scp -p` / `rsync -a` do.
+func (m *Manager) Preserve() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.preserve
+}
+
+func (m *Manager) SetPreserve(preserve bool) {
+	m.mu.Lock()
+	m.preserve = preserve
+	m.mu.Unlock()
+}
+
+func (m *Manager) Jobs() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Job(nil), m.jobs...)
+}
+
+// Clear drops finished, failed and canceled jobs from the queue.
+func (m *Manager) Clear() {
+	m.mu.Lock()
+	var jobs []*Job
+	for _, j := range m.jobs {
+		if j.state == Queued || j.state == Running || j.state == Paused {
+			jobs = append(jobs, j)
+		}
+	}
+	m.jobs = jobs
+	m.mu.Unlock()
+	m.changed()
+}
+
+// Totals sums up the progress of every job in the queue.
+func (m *Manager) Totals() (transferred, size int64, speed float64) {
+	for _, j := range m.Jobs() {
+		transferred += j.Transferred()
+		size += j.Size
+		if j.State() == Running {
+			speed += j.Speed()
+		}
+	}
+	return transferred, size, speed
+}
+
+// Download plans copying remotePath, a file or a directory ending with "/", into localPath.
+// Nothing is written until the batch is passed to Start, so conflicts can be reviewed first.
+func (m *Manager) Download(client *sftp.Client, remotePath, localPath string) (*Batch, error) {
+	if !strings.HasSuffix(remotePath, "/") {
+		info, err := client.Stat(remotePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat remote file: %v", err)
+		}
+		return m.plan(&Batch{client: client, Jobs: []*Job{m.newJob(client, Download, remotePath, localPath, info)}}), nil
+	}
+
+	batch := &Batch{client: client, preserve: m.Preserve()}
+	walker := client.Walk(remotePath)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read directory: %v", err)
+		}
+		rel := relativeTo(walker.Path(), remotePath)
+		localEntry := filepath.Join(localPath, filepath.FromSlash(rel))
+		info := walker.Stat()
+		switch {
+		case info.IsDir():
+			batch.dirs = append(batch.dirs, dirAttrs{path: localEntry, mode: info.Mode(), modTime: info.ModTime()})
+		case info.Mode()&os.ModeSymlink != 0 && batch.preserve:
+			linkTarget, err := client.ReadLink(walker.Path())
+			if err != nil {
+				return nil, fmt.Errorf("failed to read remote symlink: %v", err)
+			}
+			job := m.newJob(client, Download, walker.Path(), localEntry, info)
+			job.linkTarget = linkTarget
+			batch.Jobs = append(batch.Jobs, job)
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := client.Stat(walker.Path())
+			if err != nil || target.IsDir() {
+				continue
+			}
+			batch.Jobs = append(batch.Jobs, m.newJob(client, Download, walker.Path(), localEntry, target))
+		default:
+			batch.Jobs = append(batch.Jobs, m.newJob(client, Download, walker.Path(), localEntry, info))
+		}
+	}
+	return m.plan(batch), nil
+}
+
+// Upload plans copying localPath, a file or a directory, into remotePath.
+func (m *Manager) Upload(client *sftp.Client, localPath, remotePath string) (*Batch, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat local file: %v", err)
+	}
+	if !info.IsDir() {
+		return m.plan(&Batch{remote: true, client: client, Jobs: []*Job{m.newJob(client, Upload, localPath, remotePath, info)}}), nil
+	}
+
+	batch := &Batch{remote: true, client: client, preserve: m.Preserve()}
+	err = filepath.Walk(localPath, func(localEntry string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read local directory: %v", err)
+		}
+		rel, err := filepath.Rel(localPath, localEntry)
+		if err != nil {
+			return err
+		}
+		remoteEntry := path.Join(remotePath, filepath.ToSlash(rel))
+		switch {
+		case info.IsDir():
+			batch.dirs = append(batch.dirs, dirAttrs{path: remoteEntry, mode: info.Mode(), modTime: info.ModTime()})
+		case info.Mode()&os.ModeSymlink != 0 && batch.preserve:
+			linkTarget, err := os.Readlink(localEntry)
+			if err != nil {
+				return fmt.Errorf("failed to read local symlink: %v", err)
+			}
+			job := m.newJob(client, Upload, localEntry, remoteEntry, info)
+			job.linkTarget = filepath.ToSlash(linkTarget)
+			batch.Jobs = append(batch.Jobs, job)
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Stat(localEntry)
+			if err != nil || target.IsDir() {
+				return nil
+			}
+			batch.Jobs = append(batch.Jobs, m.newJob(client, Upload, localEntry, remoteEntry, target))
+		default:
+			batch.Jobs = append(batch.Jobs, m.newJob(client, Upload, localEntry, remoteEntry, info))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m.plan(batch), nil
+}
+
+func (m *Manager) newJob(client *sftp.Client, direction Direction, source, target string, info os.FileInfo) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		Direction: direction,
+		Source:    source,
+		Target:    target,
+		Size:      info.Size(),
+		mode:      info.Mode(),
+		modTime:   info.ModTime(),
+		preserve:  m.Preserve(),
+		client:    client,
+		manager:   m,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		job.Size = 0
+	}
+	return job
+}
+
+// plan records which targets already exist so that the caller can pick a conflict policy.
+func (m *Manager) plan(batch *Batch) *Batch {
+	for _, j := range batch.Jobs {
+		j.existing = batch.lstat(j.Target)
+	}
+	return batch
+}
+
+// Join merges planned batches of the same direction and client into one, so that
+// conflicts are reviewed and progress is reported once for all of them.
+func Join(batches ...*Batch) *Batch {
+	joined := &Batch{}
+	for _, b := range batches {
+		joined.remote = b.remote
+		joined.preserve = b.preserve
+		joined.client = b.client
+		joined.Jobs = append(joined.Jobs, b.Jobs...)
+		joined.dirs = append(joined.dirs, b.dirs...)
+	}
+	return joined
+}
+
+// Start creates the target directories and queues every job that was not skipped.
+func (m *Manager) Start(batch *Batch) error {
+	for _, dir := range batch.dirs {
+		var err error
+		if batch.remote {
+			err = batch.client.MkdirAll(dir.path)
+		} else {
+			err = os.MkdirAll(dir.path, os.ModePerm)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create directory: %v", err)
+		}
+	}
+
+	var jobs []*Job
+	for _, j := range batch.Jobs {
+		if !j.skip {
+			jobs = append(jobs, j)
+		}
+	}
+	batch.Jobs = jobs
+
+	m.mu.Lock()
+	for _, j := range batch.Jobs {
+		m.nextID++
+		j.ID = m.nextID
+		m.jobs = append(m.jobs, j)
+	}
+	m.mu.Unlock()
+	batch.done = make(chan struct{})
+	go batch.finish()
+	m.schedule()
+	return nil
+}
+
+func (m *Manager) schedule() {
+	m.mu.Lock()
+	for _, j := range m.jobs {
+		if m.running >= m.concurrency {
+			break
+		}
+		if j.state == Queued {
+			j.state = Running
+			j.started = time.Now()
+			m.running++
+			go m.run(j)
+		}
+	}
+	m.mu.Unlock()
+	m.changed()
+}
+
+func (m *Manager) run(j *Job) {
+	var err error
+	switch {
+	case j.linkTarget != "" && j.Direction == Download:
+		err = downloadSymlink(j)
+	case j.linkTarget != "":
+		err = uploadSymlink(j)
+	case j.Direction == Download:
+		err = downloadFile(j)
+	default:
+		err = uploadFile(j)
+	}
+
+	m.mu.Lock()
+	switch {
+	case j.ctx.Err() != nil:
+		j.state = Canceled
+		j.err = errors.New("canceled")
+	case err != nil:
+		j.state = Failed
+		j.err = err
+	default:
+		j.state = Done
+	}
+	j.finished = time.Now()
+	m.running--
+	close(j.done)
+	m.mu.Unlock()
+
+	m.schedule()
+}
+
+func (m *Manager) changed() {
+	if m.OnChange != nil {
+		m.OnChange()
+	}
+}
+
+func (j *Job) Name() string {
+	return path.Base(filepath.ToSlash(j.Source))
+}
+
+func (j *Job) State() State {
+	j.manager.mu.Lock()
+	defer j.manager.mu.Unlock()
+	return j.state
+}
+
+func (j *Job) Err() error {
+	j.manager.mu.Lock()
+	defer j.manager.mu.Unlock()
+	return j.err
+}
+
+func (j *Job) Transferred() int64 {
+	return j.transferred.Load()
+}
+
+// Speed returns the average throughput of the job in bytes per second, not counting resumed bytes.
+func (j *Job) Speed() float64 {
+	j.manager.mu.Lock()
+	started, finished, resumed := j.started, j.finished, j.resumed
+	j.manager.mu.Unlock()
+	if started.IsZero() {
+		return 0
+	}
+	if finished.IsZero() {
+		finished = time.Now()
+	}
+	elapsed := finished.Sub(started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(j.Transferred()-resumed) / elapsed
+}
+
+// ETA estimates the remaining time from the current throughput.
+func (j *Job) ETA() time.Duration {
+	speed := j.Speed()
+	if speed <= 0 {
+		return 0
+	}
+	return time.Duration(float64(j.Size-j.Transferred()) / speed * float64(time.Second))
+}
+
+func (j *Job) Pause() {
+	j.manager.mu.Lock()
+	if j.state == Queued || j.state == Running {
+		j.state = Paused
+		j.resume = make(chan struct{})
+	}
+	j.manager.mu.Unlock()
+	j.manager.changed()
+}
+
+func (j *Job) Resume() {
+	j.manager.mu.Lock()
+	if j.state == Paused {
+		if j.started.IsZero() {
+			j.state = Queued
+		} else {
+			j.state = Running
+		}
+		close(j.resume)
+	}
+	j.manager.mu.Unlock()
+	j.manager.schedule()
+}
+
+func (j *Job) Cancel() {
+	j.cancel()
+	j.manager.mu.Lock()
+	if j.started.IsZero() && (j.state == Queued || j.state == Paused) {
+		j.state = Canceled
+		j.err = errors.New("canceled")
+		close(j.done)
+	}
+	j.manager.mu.Unlock()
+	j.manager.changed()
+}
+
+// wait blocks while the job is paused and reports cancellation.
+func (j *Job) wait() error {
+	for {
+		j.manager.mu.Lock()
+		state, resume := j.state, j.resume
+		j.manager.mu.Unlock()
+		if state != Paused {
+			return j.ctx.Err()
+		}
+		select {
+		case <-resume:
+		case <-j.ctx.Done():
+			return j.ctx.Err()
+		}
+	}
+}
+
+// Wait blocks until every job of the batch has finished and joins their errors.
+func (b *Batch) Wait() error {
+	<-b.done
+	return b.err
+}
+
+// finish waits for the jobs and then applies directory attributes, deepest first,
+// since writing the files would otherwise bump the directory mtimes again.
+func (b *Batch) finish() {
+	var errs []error
+	for _, j := range b.Jobs {
+		<-j.done
+		if err := j.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", j.Source, err))
+		}
+	}
+	for i := len(b.dirs) - 1; i >= 0 && b.preserve; i-- {
+		dir := b.dirs[i]
+		var err error
+		if b.remote {
+			err = setRemoteAttrs(b.client, dir.path, dir.mode, dir.modTime)
+		} else {
+			err = setLocalAttrs(dir.path, dir.mode, dir.modTime)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dir.path, err))
+		}
+	}
+	b.err = errors.Join(errs...)
+	close(b.done)
+}
+
+func setLocalAttrs(path string, mode os.FileMode, modTime time.Time) error {
+	if err := os.Chmod(path, mode.Perm()); err != nil {
+		return fmt.Errorf("failed to preserve mode: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		return fmt.Errorf("failed to preserve mtime: %v", err)
+	}
+	return nil
+}
+
+func setRemoteAttrs(client *sftp.Client, path string, mode os.FileMode, modTime time.Time) error {
+	if err := client.Chmod(path, mode.Perm()); err != nil {
+		return fmt.Errorf("failed to preserve mode: %v", err)
+	}
+	if err := client.Chtimes(path, modTime, modTime); err != nil {
+		return fmt.Errorf("failed to preserve mtime: %v", err)
+	}
+	return nil
+}
+
+type progressReader struct {
+	r   io.Reader
+	job *Job
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.job.wait(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	p.job.transferred.Add(int64(n))
+	return n, err
+}
+
+type progressWriter struct {
+	w   io.Writer
+	job *Job
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.job.wait(); err != nil {
+		return 0, err
+	}
+	n, err := p.w.Write(b)
+	p.job.transferred.Add(int64(n))
+	return n, err
+}
+
+// resumeFrom marks the bytes already present in the partial file as transferred.
+func (j *Job) resumeFrom(offset int64) {
+	j.manager.mu.Lock()
+	j.resumed = offset
+	j.manager.mu.Unlock()
+	j.transferred.Store(offset)
+}
+
+// partialOffset returns how much of a partial file of partialSize bytes can be kept.
+// The prefix is compared with the source by checksum when verify is set.
+func (j *Job) partialOffset(partial, source io.ReaderAt, partialSize int64, verify bool) int64 {
+	if partialSize <= 0 || partialSize > j.Size {
+		return 0
+	}
+	if verify && !samePrefix(partial, source, partialSize) {
+		return 0
+	}
+	return partialSize
+}
+
+func samePrefix(a, b io.ReaderAt, n int64) bool {
+	hashA, hashB := sha256.New(), sha256.New()
+	if _, err := io.Copy(hashA, io.NewSectionReader(a, 0, n)); err != nil {
+		return false
+	}
+	if _, err := io.Copy(hashB, io.NewSectionReader(b, 0, n)); err != nil {
+		return false
+	}
+	return bytes.Equal(hashA.Sum(nil), hashB.Sum(nil))
+}
+
+func downloadFile(j *Job) error {
+	srcFile, err := j.client.Open(j.Source)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer srcFile.Close()
+
+	partPath := j.Target + partSuffix
+	dstFile, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %v", err)
+	}
+	defer dstFile.Close()
+
+	var partialSize int64
+	if info, err := dstFile.Stat(); err == nil {
+		partialSize = info.Size()
+	}
+	offset := j.partialOffset(dstFile, srcFile, partialSize, j.manager.VerifyResume())
+	if err := dstFile.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate local file: %v", err)
+	}
+	if _, err := dstFile.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek local file: %v", err)
+	}
+	if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek remote file: %v", err)
+	}
+	j.resumeFrom(offset)
+
+	_, err = srcFile.WriteTo(&progressWriter{w: dstFile, job: j})
+	if err != nil {
+		return fmt.Errorf("failed to copy file: %v", err)
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to close local file: %v", err)
+	}
+	if err := os.Rename(partPath, j.Target); err != nil {
+		return fmt.Errorf("failed to rename local file: %v", err)
+	}
+
+	if j.preserve {
+		return setLocalAttrs(j.Target, j.mode, j.modTime)
+	}
+	return nil
+}
+
+// renameOver moves src over dst. Servers without posix-rename refuse to
+// rename onto an existing file, so there dst is removed first.
+func renameOver(client *sftp.Client, src, dst string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		if err := client.PosixRename(src, dst); err != nil {
+			return fmt.Errorf("failed to rename remote file: %v", err)
+		}
+		return nil
+	}
+
+	removed := false
+	if _, err := client.Lstat(dst); err == nil {
+		if err := client.Remove(dst); err != nil {
+			return fmt.Errorf("failed to replace remote file: %v", err)
+		}
+		removed = true
+	}
+	if err := client.Rename(src, dst); err != nil {
+		if removed {
+			return fmt.Errorf("failed to rename remote file, the previous %s was removed and the upload is kept in %s: %v", dst, src, err)
+		}
+		return fmt.Errorf("failed to rename remote file: %v", err)
+	}
+	return nil
+}
+
+func downloadSymlink(j *Job) error {
+	if err := os.Remove(j.Target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace local file: %v", err)
+	}
+	if err := os.Symlink(filepath.FromSlash(j.linkTarget), j.Target); err != nil {
+		return fmt.Errorf("failed to create local symlink: %v", err)
+	}
+	return nil
+}
+
+func uploadSymlink(j *Job) error {
+	if err := j.client.Remove(j.Target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace remote file: %v", err)
+	}
+	if err := j.client.Symlink(j.linkTarget, j.Target); err != nil {
+		return fmt.Errorf("failed to create remote symlink: %v", err)
+	}
+	return nil
+}
+
+func uploadFile(j *Job) error {
+	srcFile, err := os.Open(j.Source)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %v", err)
+	}
+	defer srcFile.Close()
+
+	partPath := j.Target + partSuffix
+	dstFile, err := j.client.OpenFile(partPath, os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return fmt.Errorf("failed to create remote file: %v", err)
+	}
+	defer dstFile.Close()
+
+	var partialSize int64
+	if info, err := dstFile.Stat(); err == nil {
+		partialSize = info.Size()
+	}
+	// Concurrent writes can leave holes in a partial file whose failure could not
+	// be cleaned up, e.g. when the connection dropped, so its prefix is always verified.
+	offset := j.partialOffset(dstFile, srcFile, partialSize, true)
+	if err := dstFile.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate remote file: %v", err)
+	}
+	if _, err := dstFile.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek remote file: %v", err)
+	}
+	if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek local file: %v", err)
+	}
+	j.resumeFrom(offset)
+
+	_, err = dstFile.ReadFromWithConcurrency(&progressReader{r: srcFile, job: j}, 0)
+	if err != nil {
+		// After a failed concurrent write the file offset is the end of the data
+		// known to be written; later writes may have left holes past it.
+		if written, seekErr := dstFile.Seek(0, io.SeekCurrent); seekErr == nil {
+			dstFile.Truncate(written)
+		}
+		return fmt.Errorf("failed to copy file: %v", err)
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to close remote file: %v", err)
+	}
+	if err := renameOver(j.client, partPath, j.Target); err != nil {
+		return err
+	}
+
+	if j.preserve {
+		return setRemoteAttrs(j.client, j.Target, j.mode, j.modTime)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package transfer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// benchLatency is the one-way delay added to server responses, roughly a
+// long-distance link; without it every request completes at memory speed.
+const benchLatency = 2 * time.Millisecond
+
+const (
+	benchFileSize  = 4 << 20
+	benchTreeFiles = 256
+	benchTreeDirs  = 16
+)
+
+// delayed passes r through with every chunk held back by latency. Chunks are
+// delayed independently, so requests in flight overlap like on a real link.
+func delayed(r io.Reader, latency time.Duration) io.Reader {
+	type chunk struct {
+		data []byte
+		at   time.Time
+	}
+	chunks := make(chan chunk, 1024)
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer close(chunks)
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- chunk{data: buf[:n], at: time.Now().Add(latency)}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		for c := range chunks {
+			time.Sleep(time.Until(c.at))
+			if _, err := pw.Write(c.data); err != nil {
+				break
+			}
+		}
+		pw.Close()
+		for range chunks {
+		}
+	}()
+	return pr
+}
+
+// newPipeClient connects a client to an in-process SFTP server serving the local file system.
+func newPipeClient(b *testing.B, opts ...sftp.ClientOption) *sftp.Client {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter})
+	if err != nil {
+		b.Fatal(err)
+	}
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(delayed(clientReader, benchLatency), clientWriter, opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		// The server goes first so that the client's receive loop sees EOF.
+		server.Close()
+		client.Close()
+	})
+	return client
+}
+
+// writeTree fills dir with files files of size bytes, spread over subdirectories.
+func writeTree(b *testing.B, dir string, files, size int) {
+	data := bytes.Repeat([]byte("goscout "), size/8)
+	for i := 0; i < files; i++ {
+		sub := filepath.Join(dir, strconv.Itoa(i%benchTreeDirs))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, strconv.Itoa(i)), data, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkManager copies a single file or a tree of benchTreeFiles files, both
+// benchFileSize in total, through a Manager running workers jobs at a time.
+func benchmarkManager(b *testing.B, direction Direction, tree bool, workers int) {
+	source, targets := b.TempDir(), b.TempDir()
+	if tree {
+		writeTree(b, source, benchTreeFiles, benchFileSize/benchTreeFiles)
+		// Download takes a remote directory by its trailing slash.
+		source += "/"
+	} else {
+		writeTree(b, source, 1, benchFileSize)
+		source = filepath.Join(source, "0", "0")
+	}
+	client := newPipeClient(b,
+		sftp.MaxPacket(1<<15),
+		sftp.UseConcurrentReads(true),
+		sftp.UseConcurrentWrites(true),
+	)
+	m := NewManager(workers)
+
+	b.SetBytes(benchFileSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target := filepath.Join(targets, strconv.Itoa(i))
+		var batch *Batch
+		var err error
+		if direction == Upload {
+			batch, err = m.Upload(client, source, target)
+		} else {
+			batch, err = m.Download(client, source, target)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := m.Start(batch); err != nil {
+			b.Fatal(err)
+		}
+		if err := batch.Wait(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkDirection(b *testing.B, direction Direction) {
+	b.Run("file", func(b *testing.B) { benchmarkManager(b, direction, false, 4) })
+	b.Run("tree/workers=1", func(b *testing.B) { benchmarkManager(b, direction, true, 1) })
+	b.Run("tree/workers=4", func(b *testing.B) { benchmarkManager(b, direction, true, 4) })
+}
+
+// BenchmarkUpload runs Manager.Upload over a link with benchLatency of delay.
+func BenchmarkUpload(b *testing.B) {
+	benchmarkDirection(b, Upload)
+}
+
+// BenchmarkDownload runs Manager.Download over a link with benchLatency of delay.
+func BenchmarkDownload(b *testing.B) {
+	benchmarkDirection(b, Download)
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"os"
+	"path"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+func (ft *fileTable) archive(infos []scoutssh.FileInfo) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(archiveBaseName(infos) + ".tar.gz")
+
+	var formats []string
+	for _, format := range scoutssh.ArchiveFormats {
+		formats = append(formats, format.String())
+	}
+	formatSelect := widget.NewSelect(formats, func(selected string) {
+		name := strings.TrimSuffix(strings.TrimSuffix(nameEntry.Text, ".tar.gz"), ".zip")
+		nameEntry.SetText(name + "." + selected)
+	})
+	formatSelect.SetSelectedIndex(0)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel("Archive "+summarizeSelection(infos))),
+		widget.NewFormItem("Format", formatSelect),
+		widget.NewFormItem("Name", nameEntry),
+	}
+	dialog.ShowForm("Archive", "Create", "Cancel", items, func(ok bool) {
+		name := strings.TrimSpace(nameEntry.Text)
+		if !ok || name == "" {
+			return
+		}
+		dir := trimPath(ft.data.path.Text)
+		archivePath := path.Join(dir, name)
+		format := scoutssh.ArchiveFormats[formatSelect.SelectedIndex()]
+
+		go func() {
+			err := scoutssh.CreateArchive(ft.data.sftpClient, ft.data.sshClient, archivePath, dir, ft.entryPaths(infos), format)
+			ft.data.path.OnSubmitted(dir)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to create archive: %v", err), ft.ui.fyneWindow)
+				return
+			}
+			ft.ui.notifySuccess("Archive created: " + archivePath)
+		}()
+	}, ft.ui.fyneWindow)
+}
+
+func (ft *fileTable) extract(info scoutssh.FileInfo) {
+	archivePath := strings.TrimSuffix(ft.entryPath(info), "/")
+	destEntry := widget.NewEntry()
+	destEntry.SetText(trimPath(ft.data.path.Text))
+
+	items := []*widget.FormItem{widget.NewFormItem("Into", destEntry)}
+	dialog.ShowForm("Extract "+displayName(info), "Extract", "Cancel", items, func(ok bool) {
+		dest := strings.TrimSpace(destEntry.Text)
+		if !ok || dest == "" {
+			return
+		}
+		go func() {
+			err := scoutssh.ExtractArchive(ft.data.sftpClient, ft.data.sshClient, archivePath, dest)
+			ft.data.path.OnSubmitted(trimPath(ft.data.path.Text))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to extract %s: %v", displayName(info), err), ft.ui.fyneWindow)
+				return
+			}
+			ft.ui.notifySuccess(displayName(info) + " extracted to " + dest)
+		}()
+	}, ft.ui.fyneWindow)
+}
+
+// downloadTarGz packs the entries on the fly into a local .tar.gz; nothing is written remotely.
+func (ft *fileTable) downloadTarGz(infos []scoutssh.FileInfo) {
+	dir := trimPath(ft.data.path.Text)
+	paths := ft.entryPaths(infos)
+
+	fileSaveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, ft.ui.fyneWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		localPath := writer.URI().Path()
+
+		go func() {
+			err := scoutssh.StreamTarGz(ft.data.sftpClient, ft.data.sshClient, writer, dir, paths)
+			if closeErr := writer.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(localPath)
+				dialog.ShowError(fmt.Errorf("failed to download archive: %v", err), ft.ui.fyneWindow)
+				return
+			}
+			dialog.ShowInformation("Success", summarizeCount(infos)+"\nSaved in "+localPath, ft.ui.fyneWindow)
+		}()
+	}, ft.ui.fyneWindow)
+
+	fileSaveDialog.SetFileName(archiveBaseName(infos) + ".tar.gz")
+	fileSaveDialog.Resize(ft.ui.fyneWindow.Canvas().Size())
+	fileSaveDialog.Show()
+}
+
+func (ft *fileTable) entryPaths(infos []scoutssh.FileInfo) []string {
+	var paths []string
+	for _, info := range infos {
+		paths = append(paths, ft.entryPath(info))
+	}
+	return paths
+}
+
+func archiveBaseName(infos []scoutssh.FileInfo) string {
+	if len(infos) == 1 {
+		return displayName(infos[0])
+	}
+	return "archive"
+}
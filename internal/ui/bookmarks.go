@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"log"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+func (ui *UI) bookmarksButton(params UIParams) *widget.Button {
+	var button *widget.Button
+	button = widget.NewButton("⭐", func() {
+		currentPath := trimPath(params.data.path.Text)
+		bookmarks := ui.cfg.Bookmarks[params.Host]
+
+		var menuItems []*fyne.MenuItem
+		if slices.Contains(bookmarks, currentPath) {
+			menuItems = append(menuItems, fyne.NewMenuItem("Remove bookmark: "+currentPath, func() {
+				ui.removeBookmark(params.Host, currentPath)
+			}))
+		} else {
+			menuItems = append(menuItems, fyne.NewMenuItem("Add bookmark: "+currentPath, func() {
+				ui.addBookmark(params.Host, currentPath)
+			}))
+		}
+
+		if len(bookmarks) > 0 {
+			menuItems = append(menuItems, fyne.NewMenuItemSeparator())
+		}
+		for _, bookmark := range bookmarks {
+			menuItems = append(menuItems, fyne.NewMenuItem(bookmark, func() {
+				go params.data.path.OnSubmitted(bookmark)
+			}))
+		}
+
+		position := fyne.CurrentApp().Driver().AbsolutePositionForObject(button)
+		position.Y += button.Size().Height
+		widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", menuItems...), ui.fyneWindow.Canvas(), position)
+	})
+	return button
+}
+
+func (ui *UI) addBookmark(host, path string) {
+	bookmarks := append(ui.cfg.Bookmarks[host], path)
+	slices.Sort(bookmarks)
+	ui.cfg.Bookmarks[host] = bookmarks
+	ui.saveBookmarks()
+}
+
+func (ui *UI) removeBookmark(host, path string) {
+	ui.cfg.Bookmarks[host] = slices.DeleteFunc(ui.cfg.Bookmarks[host], func(bookmark string) bool {
+		return bookmark == path
+	})
+	if len(ui.cfg.Bookmarks[host]) == 0 {
+		delete(ui.cfg.Bookmarks, host)
+	}
+	ui.saveBookmarks()
+}
+
+func (ui *UI) saveBookmarks() {
+	err := SaveConfig(ui.cfg)
+	if err != nil {
+		log.Printf("Failed to save config: %v", err)
+	}
+}
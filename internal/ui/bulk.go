@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"goscout/internal/transfer"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxListedNames caps how many entries a confirmation spells out.
+const maxListedNames = 10
+
+func (ft *fileTable) showBulkMenu(e *desktop.MouseEvent) {
+	infos := ft.selection()
+	title := fmt.Sprintf("%d selected", len(infos))
+
+	menu := fyne.NewMenu("",
+		fyne.NewMenuItem(title+": download to...", func() { ft.bulkDownload(infos) }),
+		fyne.NewMenuItem(title+": move to...", func() { ft.bulkMove(infos) }),
+		fyne.NewMenuItem(title+": chmod...", func() { ft.bulkChmod(infos) }),
+		fyne.NewMenuItem(title+": archive...", func() { ft.archive(infos) }),
+		fyne.NewMenuItem(title+": download as .tar.gz...", func() { ft.downloadTarGz(infos) }),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("🔴 "+title+": remove", func() { ft.remove(infos) }),
+	)
+	popUpMenu := widget.NewPopUpMenu(menu, ft.ui.fyneWindow.Canvas())
+	popUpMenu.ShowAtPosition(e.AbsolutePosition)
+}
+
+func (ft *fileTable) bulkDownload(infos []scoutssh.FileInfo) {
+	folderOpenDialog := dialog.NewFolderOpen(func(list fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, ft.ui.fyneWindow)
+			return
+		}
+		if list == nil {
+			return
+		}
+
+		localBase := list.Path()
+		client := ft.data.sftpClient
+		ft.ui.startTransfer(func() (*transfer.Batch, error) {
+			var batches []*transfer.Batch
+			for _, info := range infos {
+				batch, err := ft.ui.transfers.Download(client, info.FullPath, filepath.Join(localBase, displayName(info)))
+				if err != nil {
+					return nil, err
+				}
+				batches = append(batches, batch)
+			}
+			return transfer.Join(batches...), nil
+		}, fmt.Sprintf("Downloaded %s to %s", summarizeCount(infos), localBase), nil)
+	}, ft.ui.fyneWindow)
+
+	folderOpenDialog.Resize(ft.ui.fyneWindow.Canvas().Size())
+	folderOpenDialog.Show()
+}
+
+func (ft *fileTable) bulkMove(infos []scoutssh.FileInfo) {
+	targetEntry := widget.NewEntry()
+	targetEntry.SetText(trimPath(ft.data.path.Text))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel("Move "+summarizeSelection(infos))),
+		widget.NewFormItem("To", targetEntry),
+	}
+	dialog.ShowForm("Move", "Move", "Cancel", items, func(ok bool) {
+		target := strings.TrimSpace(targetEntry.Text)
+		if !ok || target == "" {
+			return
+		}
+		go ft.forEach("move", infos, func(info scoutssh.FileInfo) error {
+			return ft.data.sftpClient.Rename(strings.TrimSuffix(ft.entryPath(info), "/"), path.Join(target, displayName(info)))
+		})
+	}, ft.ui.fyneWindow)
+}
+
+func (ft *fileTable) bulkChmod(infos []scoutssh.FileInfo) {
+	modeEntry := widget.NewEntry()
+	modeEntry.SetText(fmt.Sprintf("%04o", scoutssh.OctalFromFileMode(infos[0].Mode)))
+	modeEntry.Validator = func(text string) error {
+		if value, err := strconv.ParseUint(text, 8, 32); err != nil || value > 0o7777 {
+			return fmt.Errorf("octal mode expected, e.g. 0644")
+		}
+		return nil
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel("Change mode of "+summarizeSelection(infos))),
+		widget.NewFormItem("Mode", modeEntry),
+	}
+	dialog.ShowForm("chmod", "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		mode, _ := strconv.ParseUint(modeEntry.Text, 8, 32)
+		go ft.forEach("chmod", infos, func(info scoutssh.FileInfo) error {
+			return ft.data.sftpClient.Chmod(ft.entryPath(info), scoutssh.FileModeFromOctal(uint32(mode)))
+		})
+	}, ft.ui.fyneWindow)
+}
+
+// forEach applies op to every entry, refreshes the listing and reports the entries that failed.
+func (ft *fileTable) forEach(action string, infos []scoutssh.FileInfo, op func(scoutssh.FileInfo) error) {
+	var failures []string
+	for _, info := range infos {
+		if err := op(info); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", displayName(info), err))
+		}
+	}
+	ft.data.path.OnSubmitted(trimPath(ft.data.path.Text))
+
+	if len(failures) > 0 {
+		dialog.ShowError(fmt.Errorf("failed to %s %d of %d:\n%s", action, len(failures), len(infos), strings.Join(failures, "\n")), ft.ui.fyneWindow)
+		return
+	}
+	ft.ui.notifySuccess(fmt.Sprintf("%s: %s done", summarizeCount(infos), action))
+}
+
+func summarizeCount(infos []scoutssh.FileInfo) string {
+	var files, dirs int
+	var size int64
+	for _, info := range infos {
+		if info.IsDir {
+			dirs++
+		} else {
+			files++
+			size += info.Size
+		}
+	}
+
+	var parts []string
+	if files > 0 {
+		parts = append(parts, fmt.Sprintf("%d files (%s)", files, formatSize(size)))
+	}
+	if dirs > 0 {
+		parts = append(parts, fmt.Sprintf("%d folders", dirs))
+	}
+	return strings.Join(parts, " and ")
+}
+
+func summarizeSelection(infos []scoutssh.FileInfo) string {
+	return summarizeCount(infos) + ":\n" + selectionNames(infos)
+}
+
+func selectionNames(infos []scoutssh.FileInfo) string {
+	var names []string
+	for i, info := range infos {
+		if i == maxListedNames {
+			names = append(names, fmt.Sprintf("... and %d more", len(infos)-maxListedNames))
+			break
+		}
+		name := displayName(info)
+		if info.IsDir {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, "\n")
+}
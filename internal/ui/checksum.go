@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"goscout/internal/scoutssh"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showChecksum hashes a remote file and compares the result with a pasted value or a local file.
+func (ft *fileTable) showChecksum(info scoutssh.FileInfo) {
+	remotePath := strings.TrimSuffix(ft.entryPath(info), "/")
+	w := fyne.CurrentApp().NewWindow("GoScout checksum: " + displayName(info))
+
+	var algorithms []string
+	for _, algorithm := range scoutssh.HashAlgorithms {
+		algorithms = append(algorithms, algorithm.String())
+	}
+	algorithmSelect := widget.NewSelect(algorithms, nil)
+
+	sumEntry := widget.NewEntry()
+	sumEntry.Disable()
+	compareEntry := widget.NewEntry()
+	compareEntry.SetPlaceHolder("paste an expected checksum")
+	result := widget.NewLabel("")
+
+	compare := func() {
+		expected := strings.ToLower(strings.TrimSpace(compareEntry.Text))
+		switch {
+		case expected == "" || sumEntry.Text == "" || strings.HasSuffix(sumEntry.Text, "...") || strings.HasSuffix(expected, "..."):
+			result.SetText("")
+		case expected == sumEntry.Text:
+			result.SetText("✅ checksums match")
+		default:
+			result.SetText("❌ checksums differ")
+		}
+	}
+	compareEntry.OnChanged = func(string) { compare() }
+
+	algorithm := func() scoutssh.HashAlgorithm {
+		return scoutssh.HashAlgorithms[algorithmSelect.SelectedIndex()]
+	}
+	algorithmSelect.OnChanged = func(string) {
+		selected := algorithm()
+		sumEntry.SetText("hashing...")
+		compare()
+		go func() {
+			sum, err := scoutssh.Checksum(ft.data.sftpClient, ft.data.sshClient, remotePath, selected)
+			if selected != algorithm() {
+				return
+			}
+			if err != nil {
+				sumEntry.SetText("")
+				dialog.ShowError(err, w)
+				return
+			}
+			sumEntry.SetText(sum)
+			compare()
+		}()
+	}
+
+	localButton := widget.NewButton("Compare with local file...", func() {
+		fileOpenDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			localPath := reader.URI().Path()
+			selected := algorithm()
+			compareEntry.SetText("hashing " + localPath + "...")
+			go func() {
+				sum, err := scoutssh.LocalChecksum(localPath, selected)
+				if err != nil {
+					compareEntry.SetText("")
+					dialog.ShowError(err, w)
+					return
+				}
+				compareEntry.SetText(sum)
+			}()
+		}, w)
+		fileOpenDialog.Resize(w.Canvas().Size())
+		fileOpenDialog.Show()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("File", widget.NewLabel(remotePath)),
+		widget.NewFormItem("Algorithm", algorithmSelect),
+		widget.NewFormItem("Checksum", sumEntry),
+		widget.NewFormItem("Expected", container.NewBorder(nil, nil, nil, localButton, compareEntry)),
+	)
+	w.SetContent(container.NewBorder(form, nil, nil, nil, result))
+	w.Resize(fyne.NewSize(720, 240))
+	w.Show()
+	algorithmSelect.SetSelectedIndex(0)
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/transfer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// resolveConflicts shows what a batch would replace and asks for a policy.
+// It blocks until the user answers and returns false when the transfer is canceled.
+func (ui *UI) resolveConflicts(batch *transfer.Batch) bool {
+	conflicts := batch.Conflicts()
+	if len(conflicts) == 0 {
+		return true
+	}
+
+	policy, applyToAll, ok := ui.askPolicy(
+		fmt.Sprintf("%d of %d files already exist and would be replaced:", len(conflicts), len(batch.Jobs)),
+		conflicts,
+	)
+	if !ok {
+		return false
+	}
+	if applyToAll {
+		batch.Resolve(policy)
+		return true
+	}
+
+	for i, job := range conflicts {
+		policy, applyToAll, ok := ui.askPolicy(fmt.Sprintf("Conflict %d of %d:", i+1, len(conflicts)), []*transfer.Job{job})
+		if !ok {
+			return false
+		}
+		if applyToAll {
+			batch.Resolve(policy)
+			return true
+		}
+		batch.ResolveJob(job, policy)
+	}
+	return true
+}
+
+func (ui *UI) askPolicy(title string, conflicts []*transfer.Job) (transfer.Policy, bool, bool) {
+	type answer struct {
+		policy     transfer.Policy
+		applyToAll bool
+		ok         bool
+	}
+	answerChan := make(chan answer)
+
+	list := widget.NewList(
+		func() int {
+			return len(conflicts)
+		},
+		func() fyne.CanvasObject {
+			return container.NewVBox(widget.NewLabel(""), widget.NewLabel(""))
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			job := conflicts[i]
+			existing := job.Existing()
+			labels := obj.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(job.Target)
+			labels[1].(*widget.Label).SetText(fmt.Sprintf("existing %s, %s ➜ incoming %s, %s",
+				formatSize(existing.Size()), existing.ModTime().Format("2006-01-02 15:04"),
+				formatSize(job.Size), job.ModTime().Format("2006-01-02 15:04")))
+		},
+	)
+
+	var options []string
+	for _, policy := range transfer.Policies {
+		options = append(options, policy.String())
+	}
+	policySelect := widget.NewSelect(options, nil)
+	policySelect.SetSelectedIndex(0)
+	applyCheck := widget.NewCheck("apply to all", nil)
+	applyCheck.SetChecked(len(conflicts) > 1)
+
+	content := container.NewBorder(
+		widget.NewLabel(title),
+		container.NewHBox(policySelect, applyCheck),
+		nil, nil,
+		list,
+	)
+
+	confirm := dialog.NewCustomConfirm("Destination exists", "OK", "Cancel", content, func(ok bool) {
+		answerChan <- answer{
+			policy:     transfer.Policies[policySelect.SelectedIndex()],
+			applyToAll: applyCheck.Checked,
+			ok:         ok,
+		}
+	}, ui.fyneWindow)
+	confirm.Resize(fyne.NewSize(ui.fyneWindow.Canvas().Size().Width*0.8, 400))
+	confirm.Show()
+
+	a := <-answerChan
+	return a.policy, a.applyToAll, a.ok
+}
@@ -17,7 +17,6 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -33,10 +32,15 @@ import (
 
 func DefaultConfig() *Config {
 	return &Config{
-		WindowWidth:  800.0,
-		WindowHeight: 600.0,
-		SplitOffsets: make(map[string]float64),
-		OpenTabs:     []string{},
+		WindowWidth:         800.0,
+		WindowHeight:        600.0,
+		SplitOffsets:        make(map[string]float64),
+		OpenTabs:            []string{},
+		Bookmarks:           make(map[string][]string),
+		TransferConcurrency: defaultTransferConcurrency,
+		SFTPMaxPacket:       scoutssh.MaxPacket,
+		PreserveAttributes:  true,
+		Trash:               make(map[string]string),
 	}
 }
 
@@ -147,7 +151,19 @@ func (ui *UI) setupSSHSession(host string, sshClient *ssh.Client) (*terminal.Ter
 		if err := t.RunWithConnection(in, out); err != nil {
 			ui.log(host, err.Error())
 		}
-		ui.fyneTabs.RemoveIndex(ui.fyneTabs.SelectedIndex())
+		var closed *container.TabItem
+		ui.tabsMu.Lock()
+		for tab, params := range ui.remoteTabs {
+			if params.Terminal == t {
+				delete(ui.remoteTabs, tab)
+				closed = tab
+				break
+			}
+		}
+		ui.tabsMu.Unlock()
+		if closed != nil {
+			ui.fyneTabs.Remove(closed)
+		}
 		ui.log(host, "Disconnected")
 	}()
 
@@ -166,46 +182,12 @@ func (ui *UI) setupSSHSession(host string, sshClient *ssh.Client) (*terminal.Ter
 	return t, nil
 }
 
-func (ui *UI) createList(remoteTree map[string][]scoutssh.FileInfo, entryFile *widget.Entry, entryText *CustomEntry) *widget.List {
-	var items []string
-	for _, children := range remoteTree {
-		for _, child := range children {
-			items = append(items, child.Name)
-			ui.ItemStore[child.Name] = &TreeObject{FileInfo: child}
-		}
-	}
-
-	sort.Strings(items)
-
-	list := widget.NewList(
-		func() int {
-			return len(items)
-		},
-		func() fyne.CanvasObject {
-			return NewMouseDetectingLabel(ui, false, entryFile, entryText)
-		},
-		func(i widget.ListItemID, obj fyne.CanvasObject) {
-			uid := items[i]
-			node := obj.(*MouseDetectingLabel)
-			node.SetText(uid)
-			if treeObject, exists := ui.ItemStore[uid]; exists {
-				info := treeObject.FileInfo
-				node.fullPath = info.FullPath
-				node.isBranch = info.IsDir
-				node.TextStyle.Bold = info.IsDir
-			}
-		},
-	)
-
-	return list
-}
-
 func (ui *UI) saveState() {
 	ui.cfg.WindowWidth = ui.fyneWindow.Canvas().Size().Width
 	ui.cfg.WindowHeight = ui.fyneWindow.Canvas().Size().Height
 	ui.cfg.OpenTabs = []string{}
 	for _, tab := range ui.fyneTabs.Items {
-		if tab.Icon == nil {
+		if _, ok := ui.tabParams(tab); ok {
 			ui.cfg.OpenTabs = append(ui.cfg.OpenTabs, tab.Text)
 			if split := findSplitContainer(tab.Content); split != nil {
 				ui.cfg.SplitOffsets[tab.Text] = split.Offset
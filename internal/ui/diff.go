@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"goscout/internal/textdiff"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+// maxDiffSize is the largest file the diff tab loads.
+const maxDiffSize = 1 << 20
+
+// sidePair is one row of the side-by-side view; a nil side is blank.
+type sidePair struct {
+	left, right *textdiff.Line
+}
+
+// showDiff opens a tab comparing two files given as host:path of an open tab or as local paths.
+func (ui *UI) showDiff(left, right string) {
+	origin := ui.fyneTabs.Selected()
+	leftEntry := widget.NewEntry()
+	leftEntry.SetText(left)
+	rightEntry := widget.NewEntry()
+	rightEntry.SetText(right)
+	for _, entry := range []*widget.Entry{leftEntry, rightEntry} {
+		entry.SetPlaceHolder("host:/remote/path or local path")
+	}
+
+	// The comparison is swapped in under mu while the lists read it on the UI thread.
+	var (
+		mu    sync.Mutex
+		lines []textdiff.Line
+		pairs []sidePair
+		hunks []int
+		sides []int
+		hunk  = -1
+	)
+
+	unified := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(lines)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle.Monospace = true
+			return label
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			mu.Lock()
+			if i >= len(lines) {
+				mu.Unlock()
+				return
+			}
+			line := lines[i]
+			mu.Unlock()
+			setDiffLabel(obj.(*widget.Label), &line, line.Left, line.Right)
+		},
+	)
+	sideBySide := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(pairs)
+		},
+		func() fyne.CanvasObject {
+			left, right := widget.NewLabel(""), widget.NewLabel("")
+			left.TextStyle.Monospace, right.TextStyle.Monospace = true, true
+			left.Truncation, right.Truncation = fyne.TextTruncateEllipsis, fyne.TextTruncateEllipsis
+			return container.NewGridWithColumns(2, left, right)
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			labels := obj.(*fyne.Container).Objects
+			mu.Lock()
+			if i >= len(pairs) {
+				mu.Unlock()
+				return
+			}
+			pair := pairs[i]
+			mu.Unlock()
+			setDiffLabel(labels[0].(*widget.Label), pair.left, lineNumber(pair.left, true), 0)
+			setDiffLabel(labels[1].(*widget.Label), pair.right, 0, lineNumber(pair.right, false))
+		},
+	)
+	sideBySide.Hide()
+
+	status := widget.NewLabel("")
+	modeSelect := widget.NewSelect([]string{"unified", "side by side"}, func(selected string) {
+		if selected == "unified" {
+			sideBySide.Hide()
+			unified.Show()
+		} else {
+			unified.Hide()
+			sideBySide.Show()
+		}
+	})
+	modeSelect.SetSelectedIndex(0)
+
+	jump := func(step int) {
+		mu.Lock()
+		if len(hunks) == 0 {
+			mu.Unlock()
+			return
+		}
+		hunk = (hunk + step + len(hunks)) % len(hunks)
+		current, count, unifiedRow, sideRow := hunk, len(hunks), hunks[hunk], sides[hunk]
+		mu.Unlock()
+		unified.ScrollTo(unifiedRow)
+		sideBySide.ScrollTo(sideRow)
+		status.SetText(fmt.Sprintf("change %d of %d", current+1, count))
+	}
+	prevButton := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() { jump(-1) })
+	nextButton := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() { jump(1) })
+
+	compareButton := widget.NewButton("Compare", func() {
+		status.SetText("loading...")
+		leftSource, rightSource := ui.diffSource(origin, leftEntry.Text), ui.diffSource(origin, rightEntry.Text)
+		go func() {
+			leftText, err := leftSource.read()
+			if err != nil {
+				status.SetText("")
+				dialog.ShowError(fmt.Errorf("%s: %v", leftEntry.Text, err), ui.fyneWindow)
+				return
+			}
+			rightText, err := rightSource.read()
+			if err != nil {
+				status.SetText("")
+				dialog.ShowError(fmt.Errorf("%s: %v", rightEntry.Text, err), ui.fyneWindow)
+				return
+			}
+
+			newLines := textdiff.Lines(textdiff.SplitLines(leftText), textdiff.SplitLines(rightText))
+			newHunks := textdiff.Hunks(newLines)
+			newPairs, newSides := pairLines(newLines)
+			mu.Lock()
+			lines, hunks, pairs, sides, hunk = newLines, newHunks, newPairs, newSides, -1
+			mu.Unlock()
+			unified.Refresh()
+			sideBySide.Refresh()
+			if len(newHunks) == 0 {
+				status.SetText("files are identical")
+				return
+			}
+			status.SetText(fmt.Sprintf("%d changes", len(newHunks)))
+			jump(1)
+		}()
+	})
+
+	sources := widget.NewForm(
+		widget.NewFormItem("Left", leftEntry),
+		widget.NewFormItem("Right", rightEntry),
+	)
+	controls := container.NewBorder(nil, nil, nil,
+		container.NewHBox(modeSelect, prevButton, nextButton, compareButton), status)
+
+	tab := container.NewTabItemWithIcon("diff", theme.ContentCopyIcon(),
+		container.NewBorder(container.NewVBox(sources, controls), nil, nil, nil, container.NewStack(unified, sideBySide)))
+	ui.fyneTabs.Append(tab)
+	ui.fyneTabs.Select(tab)
+
+	if left != "" && right != "" {
+		compareButton.OnTapped()
+	}
+}
+
+// diffSource is a file to compare, read through client or from the local disk when client is nil.
+type diffSource struct {
+	client *sftp.Client
+	path   string
+}
+
+// diffSource resolves host:path to a tab connected to host, preferring origin, or a local path otherwise.
+func (ui *UI) diffSource(origin *container.TabItem, source string) diffSource {
+	source = strings.TrimSpace(source)
+	if host, remotePath, ok := strings.Cut(source, ":"); ok {
+		for _, tab := range append([]*container.TabItem{origin}, ui.fyneTabs.Items...) {
+			if params, ok := ui.tabParams(tab); ok && params.Host == host {
+				return diffSource{client: params.data.sftpClient, path: remotePath}
+			}
+		}
+	}
+	if strings.HasPrefix(source, "~/") {
+		source = filepath.Join(scoutssh.LocalHome, source[2:])
+	}
+	return diffSource{path: source}
+}
+
+func (s diffSource) read() (string, error) {
+	if s.client != nil {
+		return readRemoteText(s.client, s.path, maxDiffSize)
+	}
+	return readLocalText(s.path, maxDiffSize)
+}
+
+// pairLines lines up deleted and inserted runs next to each other and maps each hunk to its row.
+func pairLines(lines []textdiff.Line) ([]sidePair, []int) {
+	var pairs []sidePair
+	var sides []int
+	for i := 0; i < len(lines); {
+		if lines[i].Op == textdiff.Equal {
+			pairs = append(pairs, sidePair{left: &lines[i], right: &lines[i]})
+			i++
+			continue
+		}
+
+		sides = append(sides, len(pairs))
+		var deleted, inserted []*textdiff.Line
+		for ; i < len(lines) && lines[i].Op != textdiff.Equal; i++ {
+			if lines[i].Op == textdiff.Delete {
+				deleted = append(deleted, &lines[i])
+			} else {
+				inserted = append(inserted, &lines[i])
+			}
+		}
+		for j := 0; j < len(deleted) || j < len(inserted); j++ {
+			var pair sidePair
+			if j < len(deleted) {
+				pair.left = deleted[j]
+			}
+			if j < len(inserted) {
+				pair.right = inserted[j]
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, sides
+}
+
+func lineNumber(line *textdiff.Line, left bool) int {
+	switch {
+	case line == nil:
+		return 0
+	case left:
+		return line.Left
+	default:
+		return line.Right
+	}
+}
+
+func setDiffLabel(label *widget.Label, line *textdiff.Line, left, right int) {
+	if line == nil {
+		label.Importance = widget.MediumImportance
+		label.SetText("")
+		return
+	}
+
+	marker, importance := " ", widget.MediumImportance
+	switch line.Op {
+	case textdiff.Delete:
+		marker, importance = "-", widget.DangerImportance
+	case textdiff.Insert:
+		marker, importance = "+", widget.SuccessImportance
+	}
+	numbers := ""
+	if left > 0 || right > 0 {
+		numbers = fmt.Sprintf("%5s %5s ", formatLineNumber(left), formatLineNumber(right))
+	}
+	label.Importance = importance
+	label.SetText(numbers + marker + " " + strings.ReplaceAll(line.Text, "\t", "    "))
+}
+
+func formatLineNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
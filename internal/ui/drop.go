@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"goscout/internal/transfer"
+	"path"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+)
+
+// handleDrop uploads files and folders dropped from the OS into the directory under
+// the cursor, or into the directory shown in the active tab otherwise.
+func (ui *UI) handleDrop(pos fyne.Position, uris []fyne.URI) {
+	params, ok := ui.tabParams(ui.fyneTabs.Selected())
+	if !ok || len(uris) == 0 {
+		ui.notifyError("Open a host tab to upload dropped files")
+		return
+	}
+
+	targetDir := trimPath(params.data.path.Text)
+	if dir, ok := ui.directoryAt(params, pos); ok {
+		targetDir = dir
+	}
+
+	client := params.data.sftpClient
+	ui.startTransfer(func() (*transfer.Batch, error) {
+		var batches []*transfer.Batch
+		for _, uri := range uris {
+			localPath := uri.Path()
+			batch, err := ui.transfers.Upload(client, localPath, path.Join(targetDir, filepath.Base(localPath)))
+			if err != nil {
+				return nil, err
+			}
+			batches = append(batches, batch)
+		}
+		return transfer.Join(batches...), nil
+	}, "Dropped files uploaded to "+targetDir, func() {
+		params.data.path.OnSubmitted(trimPath(params.data.path.Text))
+	})
+}
+
+// directoryAt returns the directory row of params' table under pos.
+func (ui *UI) directoryAt(params *UIParams, pos fyne.Position) (string, bool) {
+	label := ui.hovered
+	if label == nil || !label.isBranch || label.table != params.table {
+		return "", false
+	}
+	topLeft := fyne.CurrentApp().Driver().AbsolutePositionForObject(label)
+	size := label.Size()
+	if pos.X < topLeft.X || pos.Y < topLeft.Y || pos.X > topLeft.X+size.Width || pos.Y > topLeft.Y+size.Height {
+		return "", false
+	}
+	return label.fullPath, true
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"os"
+	"path"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+func (ft *fileTable) newFolder(dir string) {
+	ft.promptPath("New folder", "Create", path.Join(dir, "new folder"), func(target string) error {
+		return ft.data.sftpClient.Mkdir(target)
+	})
+}
+
+func (ft *fileTable) newFile(dir string) {
+	ft.promptPath("New file", "Create", path.Join(dir, "new file"), func(target string) error {
+		file, err := ft.data.sftpClient.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	})
+}
+
+// rename moves the entry to the entered path, asking before it replaces an existing one.
+func (ft *fileTable) rename(info scoutssh.FileInfo) {
+	source := strings.TrimSuffix(ft.entryPath(info), "/")
+	ft.promptPath("Rename / move "+displayName(info), "Rename", source, func(target string) error {
+		if target == source {
+			return nil
+		}
+		if _, err := ft.data.sftpClient.Lstat(target); err != nil {
+			return ft.data.sftpClient.Rename(source, target)
+		}
+		if !ft.ui.confirm("Replace", target+" already exists. Replace it?") {
+			return nil
+		}
+		return ft.data.sftpClient.PosixRename(source, target)
+	})
+}
+
+func (ft *fileTable) copy(info scoutssh.FileInfo) {
+	source := strings.TrimSuffix(ft.entryPath(info), "/")
+	ft.promptPath("Copy "+displayName(info), "Copy", source+" copy", func(target string) error {
+		return scoutssh.CopyRemote(ft.data.sftpClient, ft.data.sshClient, source, target)
+	})
+}
+
+func (ft *fileTable) symlink(info scoutssh.FileInfo) {
+	source := strings.TrimSuffix(ft.entryPath(info), "/")
+	ft.promptPath("Symlink to "+displayName(info), "Create", source+".link", func(target string) error {
+		return ft.data.sftpClient.Symlink(source, target)
+	})
+}
+
+// promptPath asks for a remote path, runs action with it and refreshes the listing.
+func (ft *fileTable) promptPath(title, confirm, initial string, action func(string) error) {
+	pathEntry := widget.NewEntry()
+	pathEntry.SetText(initial)
+
+	items := []*widget.FormItem{widget.NewFormItem("Path", pathEntry)}
+	form := dialog.NewForm(title, confirm, "Cancel", items, func(ok bool) {
+		target := strings.TrimSuffix(strings.TrimSpace(pathEntry.Text), "/")
+		if !ok || target == "" {
+			return
+		}
+		go func() {
+			err := action(target)
+			ft.data.path.OnSubmitted(trimPath(ft.data.path.Text))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s failed: %v", strings.ToLower(title), err), ft.ui.fyneWindow)
+			}
+		}()
+	}, ft.ui.fyneWindow)
+	form.Resize(fyne.NewSize(ft.ui.fyneWindow.Canvas().Size().Width*0.6, form.MinSize().Height))
+	form.Show()
+}
@@ -0,0 +1,309 @@
+package ui
+
+import (
+	"goscout/internal/scoutssh"
+	"path"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	columnName = iota
+	columnSize
+	columnMode
+	columnOwner
+	columnModified
+	sortExtension
+)
+
+var tableColumns = []struct {
+	title string
+	width float32
+}{
+	{"Name", 220},
+	{"Size", 80},
+	{"Permissions", 110},
+	{"Owner", 120},
+	{"Modified", 140},
+}
+
+var sortChoices = []struct {
+	title  string
+	column int
+}{
+	{"name", columnName},
+	{"size", columnSize},
+	{"modified", columnModified},
+	{"extension", sortExtension},
+}
+
+func (ui *UI) newFileTable(host string, data *CustomEntry) *fileTable {
+	ft := &fileTable{
+		ui:       ui,
+		host:     host,
+		data:     data,
+		options:  &listOptions{},
+		selected: make(map[string]bool),
+	}
+
+	ft.table = widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(ft.items), len(tableColumns)
+		},
+		func() fyne.CanvasObject {
+			label := NewMouseDetectingLabel(ui, false, data.path, data)
+			label.table = ft
+			return label
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			node := obj.(*MouseDetectingLabel)
+			if id.Row >= len(ft.items) {
+				node.SetText("")
+				return
+			}
+			info := ft.items[id.Row].FileInfo
+			node.info = info
+			node.fullPath = info.FullPath
+			node.isBranch = info.IsDir
+			node.row = id.Row
+			node.Importance = widget.MediumImportance
+			if ft.selected[ft.entryPath(info)] {
+				node.Importance = widget.HighImportance
+			}
+			node.TextStyle.Bold = info.IsDir && id.Col == columnName
+			node.Truncation = fyne.TextTruncateEllipsis
+
+			switch id.Col {
+			case columnName:
+				node.SetText(info.Name)
+			case columnSize:
+				if info.IsDir {
+					node.SetText("")
+				} else {
+					node.SetText(formatSize(info.Size))
+				}
+			case columnMode:
+				node.SetText(info.Mode.String())
+			case columnOwner:
+				node.SetText(info.Owner + ":" + info.Group)
+			case columnModified:
+				node.SetText(info.ModTime.Format("2006-01-02 15:04"))
+			}
+		},
+	)
+
+	sortSelect := widget.NewSelect(nil, nil)
+	for _, choice := range sortChoices {
+		sortSelect.Options = append(sortSelect.Options, choice.title)
+	}
+	selectSortChoice := func() {
+		for _, choice := range sortChoices {
+			if choice.column == ft.options.column {
+				sortSelect.Selected = choice.title
+			}
+		}
+		sortSelect.Refresh()
+	}
+	selectSortChoice()
+
+	ft.table.ShowHeaderColumn = false
+	ft.table.CreateHeader = func() fyne.CanvasObject {
+		return widget.NewButton("", nil)
+	}
+	ft.table.UpdateHeader = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		header := obj.(*widget.Button)
+		if id.Col < 0 {
+			return
+		}
+		title := tableColumns[id.Col].title
+		if ft.options.column == id.Col {
+			if ft.options.desc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		header.SetText(title)
+		header.OnTapped = func() {
+			if ft.options.column == id.Col {
+				ft.options.desc = !ft.options.desc
+			} else {
+				ft.options.column = id.Col
+				ft.options.desc = false
+			}
+			selectSortChoice()
+			ft.apply()
+		}
+	}
+
+	for i, column := range tableColumns {
+		ft.table.SetColumnWidth(i, column.width)
+	}
+
+	sortSelect.OnChanged = func(selected string) {
+		for _, choice := range sortChoices {
+			if choice.title == selected && choice.column != ft.options.column {
+				ft.options.column = choice.column
+				ft.options.desc = false
+				ft.apply()
+			}
+		}
+	}
+
+	filterEntry := widget.NewEntry()
+	filterEntry.SetPlaceHolder("filter: substring or *.glob")
+	filterEntry.OnChanged = func(text string) {
+		ft.options.filter = text
+		ft.apply()
+	}
+
+	dotfilesCheck := widget.NewCheck("hide dotfiles", func(checked bool) {
+		ft.options.hideDotfiles = checked
+		ft.apply()
+	})
+
+	controls := container.NewBorder(nil, nil, nil, container.NewHBox(sortSelect, dotfilesCheck), filterEntry)
+	ft.content = container.NewBorder(controls, nil, nil, nil, ft.table)
+
+	return ft
+}
+
+func (ft *fileTable) setData(treeData map[string][]scoutssh.FileInfo) {
+	all := []*TreeObject{}
+	for dir, children := range treeData {
+		ft.dir = dir
+		for _, child := range children {
+			all = append(all, &TreeObject{FileInfo: child})
+		}
+	}
+	ft.all = all
+	ft.selected = make(map[string]bool)
+	ft.apply()
+	ft.table.ScrollToTop()
+}
+
+func (ft *fileTable) apply() {
+	ft.items = filterItems(ft.all, ft.options)
+	sortItems(ft.items, ft.options)
+	ft.table.Refresh()
+}
+
+// entryPath is the path of the entry itself; unlike FullPath it is not resolved for links.
+func (ft *fileTable) entryPath(info scoutssh.FileInfo) string {
+	entry := path.Join(ft.dir, displayName(info))
+	if info.IsDir && !info.IsLink {
+		entry += "/"
+	}
+	return entry
+}
+
+func (ft *fileTable) toggle(row int) {
+	entry := ft.entryPath(ft.items[row].FileInfo)
+	if ft.selected[entry] {
+		delete(ft.selected, entry)
+	} else {
+		ft.selected[entry] = true
+	}
+	ft.anchor = row
+	ft.table.Refresh()
+}
+
+func (ft *fileTable) selectRange(row int) {
+	from, to := ft.anchor, row
+	if from > to {
+		from, to = to, from
+	}
+	ft.selected = make(map[string]bool)
+	for i := from; i <= to && i < len(ft.items); i++ {
+		ft.selected[ft.entryPath(ft.items[i].FileInfo)] = true
+	}
+	ft.table.Refresh()
+}
+
+func (ft *fileTable) clearSelection(row int) {
+	ft.anchor = row
+	if len(ft.selected) == 0 {
+		return
+	}
+	ft.selected = make(map[string]bool)
+	ft.table.Refresh()
+}
+
+// selection returns the selected entries in display order.
+func (ft *fileTable) selection() []scoutssh.FileInfo {
+	var infos []scoutssh.FileInfo
+	for _, item := range ft.items {
+		if ft.selected[ft.entryPath(item.FileInfo)] {
+			infos = append(infos, item.FileInfo)
+		}
+	}
+	return infos
+}
+
+func displayName(info scoutssh.FileInfo) string {
+	if info.IsLink {
+		return strings.TrimSuffix(info.Name, "*")
+	}
+	return info.Name
+}
+
+func filterItems(all []*TreeObject, options *listOptions) []*TreeObject {
+	pattern := strings.ToLower(options.filter)
+	isGlob := strings.ContainsAny(pattern, "*?[")
+
+	var items []*TreeObject
+	for _, item := range all {
+		name := displayName(item.FileInfo)
+		if options.hideDotfiles && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if pattern != "" {
+			lower := strings.ToLower(name)
+			if isGlob {
+				if matched, err := path.Match(pattern, lower); err != nil || !matched {
+					continue
+				}
+			} else if !strings.Contains(lower, pattern) {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func sortItems(items []*TreeObject, order *listOptions) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].FileInfo, items[j].FileInfo
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+
+		var less, greater bool
+		switch order.column {
+		case columnSize:
+			less, greater = a.Size < b.Size, a.Size > b.Size
+		case columnMode:
+			less, greater = a.Mode.String() < b.Mode.String(), a.Mode.String() > b.Mode.String()
+		case columnOwner:
+			less, greater = a.Owner < b.Owner, a.Owner > b.Owner
+		case columnModified:
+			less, greater = a.ModTime.Before(b.ModTime), a.ModTime.After(b.ModTime)
+		case sortExtension:
+			extA, extB := strings.ToLower(path.Ext(displayName(a))), strings.ToLower(path.Ext(displayName(b)))
+			less, greater = extA < extB, extA > extB
+		}
+		if !less && !greater {
+			less = strings.ToLower(a.Name) < strings.ToLower(b.Name)
+			greater = strings.ToLower(a.Name) > strings.ToLower(b.Name)
+		}
+		if order.desc {
+			return greater
+		}
+		return less
+	})
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"path"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+func (ui *UI) newDirTree(client *sftp.Client, data *CustomEntry) *dirTree {
+	dt := &dirTree{
+		client:   client,
+		data:     data,
+		children: make(map[string][]string),
+	}
+
+	dt.tree = widget.NewTree(
+		func(uid widget.TreeNodeID) []widget.TreeNodeID {
+			if uid == "" {
+				return []widget.TreeNodeID{"/"}
+			}
+			dt.mu.Lock()
+			defer dt.mu.Unlock()
+			return dt.children[uid]
+		},
+		func(uid widget.TreeNodeID) bool {
+			return true
+		},
+		func(branch bool) fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(uid widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			if uid == "/" {
+				label.SetText("/")
+			} else {
+				label.SetText(path.Base(uid) + "/")
+			}
+		},
+	)
+
+	dt.tree.OnBranchOpened = func(uid widget.TreeNodeID) {
+		if !dt.loaded(uid) {
+			go func() {
+				if err := dt.load(uid); err != nil {
+					ui.notifyError(fmt.Sprintf("Failed to list files: %v", err))
+				}
+			}()
+		}
+	}
+
+	dt.tree.OnSelected = func(uid widget.TreeNodeID) {
+		if uid != dt.data.path.Text {
+			go dt.data.path.OnSubmitted(uid)
+		}
+	}
+
+	return dt
+}
+
+func (dt *dirTree) loaded(dir string) bool {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	_, ok := dt.children[dir]
+	return ok
+}
+
+func (dt *dirTree) load(dir string) error {
+	treeData, err := scoutssh.FetchSFTPData(dt.client, dt.data.owners, dir)
+	if err != nil {
+		return err
+	}
+	dt.setData(dir, treeData)
+	return nil
+}
+
+// setData stores the subdirectories of dir; symlinks are skipped so that every node stays unique.
+func (dt *dirTree) setData(dir string, treeData map[string][]scoutssh.FileInfo) {
+	children := []string{}
+	for _, entries := range treeData {
+		for _, entry := range entries {
+			if entry.IsDir && !entry.IsLink {
+				children = append(children, entry.FullPath)
+			}
+		}
+	}
+	sort.Strings(children)
+
+	dt.mu.Lock()
+	dt.children[dir] = children
+	dt.mu.Unlock()
+	dt.tree.Refresh()
+}
+
+// reveal expands every ancestor of dir, loading them on demand, and selects it.
+func (dt *dirTree) reveal(dir string) {
+	current := "/"
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if !dt.loaded(current) {
+			if err := dt.load(current); err != nil {
+				return
+			}
+		}
+		dt.tree.OpenBranch(current)
+		if part == "" {
+			break
+		}
+		current += part + "/"
+	}
+	dt.tree.Select(dir)
+	dt.tree.ScrollTo(dir)
+}
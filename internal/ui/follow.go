@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	followInterval = time.Second
+	// followMaxRead bounds how much new data one poll reads; older bytes are skipped.
+	followMaxRead = 1 << 20
+)
+
+var followLineCaps = []int{1000, 5000, 20000}
+
+func (v *fileViewer) newTailList() *widget.List {
+	list := widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.tail)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle.Monospace = true
+			return label
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			v.mu.Lock()
+			line, highlight := "", v.highlight
+			if i < len(v.tail) {
+				line = v.tail[i]
+			}
+			v.mu.Unlock()
+
+			label := obj.(*widget.Label)
+			label.Importance = widget.MediumImportance
+			if highlight != "" && strings.Contains(strings.ToLower(line), strings.ToLower(highlight)) {
+				label.Importance = widget.WarningImportance
+			}
+			label.SetText(line)
+		},
+	)
+	return list
+}
+
+// startFollow shows the end of the file and polls its size for appended data.
+func (v *fileViewer) startFollow() {
+	v.mu.Lock()
+	if v.following {
+		v.mu.Unlock()
+		return
+	}
+	file, size := v.file, v.size
+	v.mu.Unlock()
+
+	// The start of the last lines is looked up without mu, which the tail list needs to draw.
+	var pos int64
+	if size > viewerPageSize {
+		pos = nextLineStart(file, size, size-viewerPageSize)
+	}
+
+	v.mu.Lock()
+	if v.following {
+		v.mu.Unlock()
+		return
+	}
+	v.following, v.paused = true, false
+	v.tail, v.pending = nil, ""
+	v.followPos = pos
+	v.stop = make(chan struct{})
+	stop := v.stop
+	v.mu.Unlock()
+
+	v.scroll.Hide()
+	v.tailList.Show()
+	v.poll()
+
+	go func() {
+		ticker := time.NewTicker(followInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				v.poll()
+			}
+		}
+	}()
+}
+
+func (v *fileViewer) stopFollow() {
+	v.mu.Lock()
+	if !v.following {
+		v.mu.Unlock()
+		return
+	}
+	v.following = false
+	close(v.stop)
+	v.mu.Unlock()
+
+	v.tailList.Hide()
+	v.scroll.Show()
+	v.load(v.lastPage(), false)
+}
+
+func (v *fileViewer) setPaused(paused bool) {
+	v.mu.Lock()
+	v.paused = paused
+	v.mu.Unlock()
+	if !paused {
+		v.poll()
+	}
+}
+
+// poll appends whatever was written since the last poll and scrolls to it.
+func (v *fileViewer) poll() {
+	if v.read() {
+		v.tailList.Refresh()
+		v.tailList.ScrollToBottom()
+	}
+}
+
+// read takes the new bytes into the tail and reports whether it changed. A file
+// that shrank was truncated or rotated, so it is reopened and followed from its start.
+// The remote calls run without v.mu, which the window needs to draw the tail.
+func (v *fileViewer) read() bool {
+	v.readMu.Lock()
+	defer v.readMu.Unlock()
+
+	v.mu.Lock()
+	if !v.following || v.paused {
+		v.mu.Unlock()
+		return false
+	}
+	file, pos := v.file, v.followPos
+	v.mu.Unlock()
+
+	info, err := v.client.Stat(v.path)
+	if err != nil {
+		v.status.SetText(fmt.Sprintf("waiting for %s: %v", v.path, err))
+		return false
+	}
+	size := info.Size()
+	var markers []string
+	if size < pos {
+		reopened, err := v.client.Open(v.path)
+		if err != nil {
+			v.status.SetText(fmt.Sprintf("failed to reopen %s: %v", v.path, err))
+			return false
+		}
+		file, pos = reopened, 0
+		markers = append(markers, "--- file truncated or rotated ---\n")
+	}
+	if size-pos > followMaxRead {
+		markers = append(markers, fmt.Sprintf("--- skipped %s ---\n", formatSize(size-followMaxRead-pos)))
+		pos = size - followMaxRead
+	}
+	data := make([]byte, size-pos)
+	n, err := file.ReadAt(data, pos)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.following {
+		if file != v.file {
+			file.Close()
+		}
+		return false
+	}
+	if file != v.file {
+		v.file.Close()
+		v.file = file
+	}
+	for _, marker := range markers {
+		v.appendLines(marker)
+		v.pending = ""
+	}
+	v.size = size
+	v.followPos = pos
+	if err != nil && err != io.EOF {
+		v.status.SetText(fmt.Sprintf("failed to read: %v", err))
+		return len(markers) > 0
+	}
+	v.followPos += int64(n)
+	v.appendLines(strings.ToValidUTF8(string(bytes.ReplaceAll(data[:n], []byte{'\t'}, []byte("    "))), "�"))
+	v.status.SetText(v.followStatus())
+	return n > 0 || len(markers) > 0
+}
+
+// appendLines adds complete lines to the tail, keeps the unterminated rest
+// for the next poll and drops the oldest lines past the cap.
+func (v *fileViewer) appendLines(text string) {
+	text = v.pending + text
+	lines := strings.Split(text, "\n")
+	v.pending = lines[len(lines)-1]
+	v.tail = append(v.tail, lines[:len(lines)-1]...)
+	if extra := len(v.tail) - v.lineCap; extra > 0 {
+		v.tail = append([]string(nil), v.tail[extra:]...)
+	}
+}
+
+func (v *fileViewer) followStatus() string {
+	return fmt.Sprintf("following, %d lines shown, %s", len(v.tail), formatSize(v.size))
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+const (
+	hexRowSize   = 16
+	hexChunkSize = 64 * 1024
+	// hexMaxChunks bounds the memory of a hex viewer to hexMaxChunks*hexChunkSize.
+	hexMaxChunks = 16
+	// sniffSize is how much of a file openViewer inspects to tell text from binary.
+	sniffSize = 8192
+)
+
+// openViewer opens the text viewer for files whose start the editor would accept
+// as text and the hex viewer for the rest.
+func (ui *UI) openViewer(client *sftp.Client, fullPath string) {
+	file, err := client.Open(fullPath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to open %s: %v", fullPath, err), ui.fyneWindow)
+		return
+	}
+	head := make([]byte, sniffSize)
+	n, _ := file.ReadAt(head, 0)
+	file.Close()
+
+	head = head[:n]
+	if n == sniffSize {
+		head = trimPartialRune(head)
+	}
+	if isReadable(head) {
+		ui.showViewer(client, fullPath)
+	} else {
+		ui.showHexViewer(client, fullPath)
+	}
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off at the end of b.
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+func (ui *UI) showHexViewer(client *sftp.Client, fullPath string) {
+	file, err := client.Open(fullPath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to open %s: %v", fullPath, err), ui.fyneWindow)
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		dialog.ShowError(fmt.Errorf("failed to stat %s: %v", fullPath, err), ui.fyneWindow)
+		return
+	}
+
+	h := &hexViewer{
+		file:    file,
+		size:    info.Size(),
+		chunks:  make(map[int64][]byte),
+		loading: make(map[int64]bool),
+		status:  widget.NewLabel(""),
+	}
+	h.list = widget.NewList(
+		func() int {
+			return int((h.size + hexRowSize - 1) / hexRowSize)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle.Monospace = true
+			return label
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(h.row(int64(i)))
+		},
+	)
+
+	w := fyne.CurrentApp().NewWindow("GoScout hex: " + path.Base(fullPath))
+	status := h.status
+	status.SetText(fmt.Sprintf("%s, %d bytes", fullPath, h.size))
+	offsetEntry := widget.NewEntry()
+	offsetEntry.SetPlaceHolder("go to offset, e.g. 0x1f40 or 8000")
+	offsetEntry.OnSubmitted = func(text string) {
+		offset, err := strconv.ParseInt(strings.TrimSpace(text), 0, 64)
+		if err != nil || offset < 0 || offset >= h.size {
+			status.SetText(fmt.Sprintf("offset must be between 0 and %d", h.size-1))
+			return
+		}
+		row := offset / hexRowSize
+		h.list.ScrollTo(widget.ListItemID(row))
+		h.list.Select(widget.ListItemID(row))
+		status.SetText(fmt.Sprintf("offset %#x (%d)", offset, offset))
+	}
+
+	w.SetOnClosed(func() {
+		h.mu.Lock()
+		h.file.Close()
+		h.mu.Unlock()
+	})
+	w.SetContent(container.NewBorder(offsetEntry, status, nil, nil, h.list))
+	w.Resize(fyne.NewSize(760, 600))
+	w.Show()
+}
+
+// row formats one line of the dump, starting a chunk load when its bytes are not cached yet.
+func (h *hexViewer) row(i int64) string {
+	offset := i * hexRowSize
+	data, ok := h.bytesAt(offset)
+	if !ok {
+		return fmt.Sprintf("%08x  ...", offset)
+	}
+
+	var hexPart, asciiPart strings.Builder
+	for j := 0; j < hexRowSize; j++ {
+		if j == hexRowSize/2 {
+			hexPart.WriteByte(' ')
+		}
+		if j >= len(data) {
+			hexPart.WriteString("   ")
+			continue
+		}
+		fmt.Fprintf(&hexPart, "%02x ", data[j])
+		if data[j] >= ' ' && data[j] <= '~' {
+			asciiPart.WriteByte(data[j])
+		} else {
+			asciiPart.WriteByte('.')
+		}
+	}
+	return fmt.Sprintf("%08x  %s |%s|", offset, hexPart.String(), asciiPart.String())
+}
+
+func (h *hexViewer) bytesAt(offset int64) ([]byte, bool) {
+	index := offset / hexChunkSize
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	chunk, ok := h.chunks[index]
+	if !ok {
+		if !h.loading[index] {
+			h.loading[index] = true
+			go h.load(index)
+		}
+		return nil, false
+	}
+	h.touch(index)
+	start := offset - index*hexChunkSize
+	if start >= int64(len(chunk)) {
+		return nil, true
+	}
+	end := start + hexRowSize
+	if end > int64(len(chunk)) {
+		end = int64(len(chunk))
+	}
+	return chunk[start:end], true
+}
+
+// touch moves index to the back of order, which runs from least to most recently used.
+func (h *hexViewer) touch(index int64) {
+	for i, cached := range h.order {
+		if cached == index {
+			h.order = append(h.order[:i], h.order[i+1:]...)
+			break
+		}
+	}
+	h.order = append(h.order, index)
+}
+
+// load reads chunk index and evicts the least recently used chunk past hexMaxChunks.
+// A failed read is not cached, so the rows retry it when they are drawn again.
+func (h *hexViewer) load(index int64) {
+	chunk := make([]byte, hexChunkSize)
+	n, err := h.file.ReadAt(chunk, index*hexChunkSize)
+
+	h.mu.Lock()
+	delete(h.loading, index)
+	if err != nil && err != io.EOF {
+		h.mu.Unlock()
+		h.status.SetText(fmt.Sprintf("failed to read at %#x: %v", index*hexChunkSize, err))
+		return
+	}
+	h.chunks[index] = chunk[:n]
+	h.touch(index)
+	if len(h.order) > hexMaxChunks {
+		delete(h.chunks, h.order[0])
+		h.order = h.order[1:]
+	}
+	h.mu.Unlock()
+
+	h.list.Refresh()
+}
@@ -1,16 +1,19 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"goscout/internal/scoutssh"
+	"goscout/internal/transfer"
 	"io"
+	"os"
 	"path"
-	"path/filepath"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
 )
 
 func NewMouseDetectingLabel(ui *UI, isBranch bool, entryFile *widget.Entry, entryText *CustomEntry) *MouseDetectingLabel {
@@ -27,11 +30,38 @@ func NewMouseDetectingLabel(ui *UI, isBranch bool, entryFile *widget.Entry, entr
 
 func (m *MouseDetectingLabel) MouseUp(e *desktop.MouseEvent) {}
 
+func (m *MouseDetectingLabel) MouseIn(e *desktop.MouseEvent) {
+	m.ui.hovered = m
+}
+
+func (m *MouseDetectingLabel) MouseMoved(e *desktop.MouseEvent) {}
+
+func (m *MouseDetectingLabel) MouseOut() {
+	if m.ui.hovered == m {
+		m.ui.hovered = nil
+	}
+}
+
 func (m *MouseDetectingLabel) MouseDown(e *desktop.MouseEvent) {
 	switch e.Button {
 	case desktop.MouseButtonPrimary:
+		if m.table != nil {
+			switch {
+			case e.Modifier&fyne.KeyModifierShift != 0:
+				m.table.selectRange(m.row)
+				return
+			case e.Modifier&(fyne.KeyModifierControl|fyne.KeyModifierSuper) != 0:
+				m.table.toggle(m.row)
+				return
+			}
+			m.table.clearSelection(m.row)
+		}
 		go m.entryFile.OnSubmitted(m.fullPath)
 	case desktop.MouseButtonSecondary:
+		if m.table != nil && m.table.selected[m.table.entryPath(m.info)] {
+			m.table.showBulkMenu(e)
+			return
+		}
 		m.showContextMenu(e)
 	}
 }
@@ -39,15 +69,34 @@ func (m *MouseDetectingLabel) MouseDown(e *desktop.MouseEvent) {
 func (m *MouseDetectingLabel) showContextMenu(e *desktop.MouseEvent) {
 	var menuItems []*fyne.MenuItem
 	mainPath := trimPath((m.fullPath))
-	if m.isBranch {
-		menuItems = append(menuItems, fyne.NewMenuItem("Action in folder: "+m.fullPath, func() {
-			// Branch-specific action logic
+	folderItem := fyne.NewMenuItem("Action in folder: "+mainPath, nil)
+	folderItem.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("New folder...", func() { m.table.newFolder(mainPath) }),
+		fyne.NewMenuItem("New file...", func() { m.table.newFile(mainPath) }),
+	)
+	menuItems = append(menuItems, folderItem)
+	menuItems = append(menuItems, fyne.NewMenuItem("Rename / move...", func() { m.table.rename(m.info) }))
+	menuItems = append(menuItems, fyne.NewMenuItem("Copy to...", func() { m.table.copy(m.info) }))
+	menuItems = append(menuItems, fyne.NewMenuItem("Create symlink...", func() { m.table.symlink(m.info) }))
+	menuItems = append(menuItems, fyne.NewMenuItem("Properties...", func() { m.table.showProperties(m.info) }))
+	menuItems = append(menuItems, fyne.NewMenuItem("Archive...", func() { m.table.archive([]scoutssh.FileInfo{m.info}) }))
+	menuItems = append(menuItems, fyne.NewMenuItem("Download as .tar.gz...", func() { m.table.downloadTarGz([]scoutssh.FileInfo{m.info}) }))
+	if !m.info.IsDir {
+		menuItems = append(menuItems, fyne.NewMenuItem("Open in viewer", func() {
+			go m.ui.showViewer(m.table.data.sftpClient, m.table.entryPath(m.info))
 		}))
-	} else {
-		menuItems = append(menuItems, fyne.NewMenuItem("Action in folder: "+filepath.Dir(m.fullPath)+"/", func() {
-			// Branch-specific action logic
+		menuItems = append(menuItems, fyne.NewMenuItem("Open in hex viewer", func() {
+			go m.ui.showHexViewer(m.table.data.sftpClient, m.table.entryPath(m.info))
 		}))
+		menuItems = append(menuItems, fyne.NewMenuItem("Checksum...", func() { m.table.showChecksum(m.info) }))
+		menuItems = append(menuItems, fyne.NewMenuItem("Compare with...", func() {
+			m.ui.showDiff(m.table.host+":"+m.table.entryPath(m.info), "")
+		}))
+	}
+	if !m.info.IsDir && scoutssh.IsArchive(displayName(m.info)) {
+		menuItems = append(menuItems, fyne.NewMenuItem("Extract here...", func() { m.table.extract(m.info) }))
 	}
+	menuItems = append(menuItems, fyne.NewMenuItemSeparator())
 
 	// Common action for both folders and files
 	menuItems = append(menuItems, fyne.NewMenuItem("Upload file", func() {
@@ -64,13 +113,12 @@ func (m *MouseDetectingLabel) showContextMenu(e *desktop.MouseEvent) {
 			localPath := reader.URI().Path()
 			remotePath := path.Join(mainPath, path.Base(localPath))
 
-			err = uploadFile(m.ui.activeSFTP[m.ui.fyneTabs.SelectedIndex()], localPath, remotePath)
-			if err != nil {
-				dialog.ShowError(err, m.ui.fyneWindow)
-			} else {
-				dialog.ShowInformation("Success", "File uploaded successfully", m.ui.fyneWindow)
-				go m.entryFile.OnSubmitted(mainPath)
-			}
+			client := m.table.data.sftpClient
+			m.ui.startTransfer(func() (*transfer.Batch, error) {
+				return m.ui.transfers.Upload(client, localPath, remotePath)
+			}, "File uploaded successfully", func() {
+				m.entryFile.OnSubmitted(mainPath)
+			})
 		}, m.ui.fyneWindow)
 
 		screenSize := m.ui.fyneWindow.Canvas().Size()
@@ -91,13 +139,12 @@ func (m *MouseDetectingLabel) showContextMenu(e *desktop.MouseEvent) {
 			localPath := list.Path()
 			remotePath := path.Join(mainPath, path.Base(localPath))
 
-			err = uploadDirectory(m.ui.activeSFTP[m.ui.fyneTabs.SelectedIndex()], localPath, remotePath)
-			if err != nil {
-				dialog.ShowError(err, m.ui.fyneWindow)
-			} else {
-				dialog.ShowInformation("Success", "Directory uploaded successfully", m.ui.fyneWindow)
-				go m.entryFile.OnSubmitted(mainPath)
-			}
+			client := m.table.data.sftpClient
+			m.ui.startTransfer(func() (*transfer.Batch, error) {
+				return m.ui.transfers.Upload(client, localPath, remotePath)
+			}, "Directory uploaded successfully", func() {
+				m.entryFile.OnSubmitted(mainPath)
+			})
 		}, m.ui.fyneWindow)
 
 		screenSize := m.ui.fyneWindow.Canvas().Size()
@@ -106,12 +153,8 @@ func (m *MouseDetectingLabel) showContextMenu(e *desktop.MouseEvent) {
 	}))
 
 	menuItems = append(menuItems, fyne.NewMenuItemSeparator())
-	menuItems = append(menuItems, fyne.NewMenuItem("🔴 remove: "+m.Text, func() {
-		path, err := scoutssh.RemoveSFTP(m.ui.activeSFTP[m.ui.fyneTabs.SelectedIndex()], m.fullPath)
-		if err == nil {
-			go m.entryFile.OnSubmitted(path)
-		}
-
+	menuItems = append(menuItems, fyne.NewMenuItem("🔴 remove: "+displayName(m.info), func() {
+		m.table.remove([]scoutssh.FileInfo{m.info})
 	}))
 
 	menu := fyne.NewMenu("", menuItems...)
@@ -119,60 +162,99 @@ func (m *MouseDetectingLabel) showContextMenu(e *desktop.MouseEvent) {
 	popUpMenu.ShowAtPosition(e.AbsolutePosition)
 }
 
-func (ui *UI) handleSelection(fullPath string) *widget.Entry {
-	selectedTabIndex := ui.fyneTabs.SelectedIndex()
-	entryText := &widget.Entry{}
+// maxDisplaySize is the largest file handleSelection loads into the editor.
+const maxDisplaySize = 32 * 1024
+
+// handleSelection loads fullPath for the editor. viewable reports a file the
+// editor refused that a viewer can show, because it is too large or binary.
+func (ui *UI) handleSelection(client *sftp.Client, fullPath string) (entryText *widget.Entry, viewable bool) {
+	entryText = &widget.Entry{}
 
-	fileInfo, err := ui.activeSFTP[selectedTabIndex].Stat(fullPath)
+	content, err := readRemoteText(client, fullPath, maxDisplaySize)
+	ui.fyneWindow.Content().Refresh()
+	var tooLarge *tooLargeError
+	if errors.As(err, &tooLarge) || errors.Is(err, errUnreadable) {
+		entryText.SetText(fullPath + "\n" + err.Error() + " in the editor, use Open in viewer")
+		entryText.TextStyle = fyne.TextStyle{Bold: true, Italic: true}
+		return entryText, true
+	}
 	if err != nil {
-		entryText.SetText(fullPath + "\n" + fmt.Sprintf("Failed to get file info: %v", err))
+		entryText.SetText(fullPath + "\n" + err.Error())
 		entryText.TextStyle = fyne.TextStyle{Bold: true, Italic: true}
-		return entryText
-	}
-
-	if fileInfo.Size() > 32*1024 {
-		var sizeStr string
-		const unit = 1024
-		if fileInfo.Size() < unit {
-			sizeStr = fmt.Sprintf("%d B", fileInfo.Size())
-		} else {
-			div, exp := int64(unit), 0
-			for n := fileInfo.Size() / unit; n >= unit; n /= unit {
-				div *= unit
-				exp++
-			}
+		return entryText, false
+	}
 
-			sizeStr = fmt.Sprintf("%.1f %cB", float64(fileInfo.Size())/float64(div), "KMGTPE"[exp])
-		}
+	entryText.SetText(content)
+	entryText.TextStyle = fyne.TextStyle{}
+	return entryText, false
+}
 
-		ui.fyneWindow.Content().Refresh()
+var errUnreadable = errors.New("File contains unreadable symbols")
 
-		entryText.SetText(fullPath + "\nFile too large to display, " + sizeStr)
-		entryText.TextStyle = fyne.TextStyle{Bold: true, Italic: true}
-		return entryText
+// tooLargeError reports a file over the display limit.
+type tooLargeError struct {
+	size int64
+}
+
+func (e *tooLargeError) Error() string {
+	return "File too large to display, " + formatSize(e.size)
+}
+
+// readRemoteText loads a remote file for display, refusing files over limit and binary content.
+func readRemoteText(client *sftp.Client, fullPath string, limit int64) (string, error) {
+	fileInfo, err := client.Stat(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get file info: %v", err)
+	}
+	if fileInfo.Size() > limit {
+		return "", &tooLargeError{size: fileInfo.Size()}
 	}
 
-	file, err := ui.activeSFTP[selectedTabIndex].Open(fullPath)
+	file, err := client.Open(fullPath)
 	if err != nil {
-		entryText.SetText(fullPath + "\n" + fmt.Sprintf("Failed to open file: %v", err))
-		entryText.TextStyle = fyne.TextStyle{Bold: true, Italic: true}
-		return entryText
+		return "", fmt.Errorf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	return readText(file)
+}
+
+func readLocalText(fullPath string, limit int64) (string, error) {
+	fileInfo, err := os.Stat(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get file info: %v", err)
+	}
+	if fileInfo.Size() > limit {
+		return "", &tooLargeError{size: fileInfo.Size()}
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed to open file: %v", err)
 	}
 	defer file.Close()
+	return readText(file)
+}
 
-	content, err := io.ReadAll(file)
+func readText(r io.Reader) (string, error) {
+	content, err := io.ReadAll(r)
 	if err != nil {
-		ui.notifyError(fmt.Sprintf("Failed to read file: %v", err))
-		return entryText
+		return "", fmt.Errorf("Failed to read file: %v", err)
+	}
+	if !isReadable(content) {
+		return "", errUnreadable
 	}
+	return string(content), nil
+}
 
-	ui.fyneWindow.Content().Refresh()
-	if isReadable(content) {
-		entryText.SetText(string(content))
-		entryText.TextStyle = fyne.TextStyle{}
-	} else {
-		entryText.SetText(fullPath + "\nFile contains unreadable symbols")
-		entryText.TextStyle = fyne.TextStyle{Bold: true, Italic: true}
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
 	}
-	return entryText
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
 }
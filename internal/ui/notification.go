@@ -1,6 +1,9 @@
 package ui
 
-import "fyne.io/fyne/v2"
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
 
 func (ui *UI) notifyError(message string) {
 	fyne.CurrentApp().SendNotification(&fyne.Notification{
@@ -16,6 +19,15 @@ func (ui *UI) notifySuccess(message string) {
 	})
 }
 
+// confirm shows a yes/no dialog and blocks until it is answered, so it must not run on the UI goroutine.
+func (ui *UI) confirm(title, message string) bool {
+	answer := make(chan bool)
+	dialog.ShowConfirm(title, message, func(ok bool) {
+		answer <- ok
+	}, ui.fyneWindow)
+	return <-answer
+}
+
 func (ui *UI) log(host, message string) {
 	//dialog.ShowError(err, ui.fyneWindow)
 	if ui.logsLabel.Text != "" {
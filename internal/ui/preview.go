@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+const (
+	// maxImageSize is the largest image the preview downloads.
+	maxImageSize = 20 << 20
+	// maxImagePixels caps the decoded size, which a small compressed file can blow up.
+	maxImagePixels = 64 << 20
+	// svgDefaultSize is used for SVGs that declare neither width/height nor a viewBox.
+	svgDefaultSize = 512
+	zoomStep       = 1.25
+	minZoom        = 0.05
+	maxZoom        = 16
+)
+
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".svg":  true,
+}
+
+func isImage(fullPath string) bool {
+	return imageExtensions[strings.ToLower(path.Ext(fullPath))]
+}
+
+func newImagePreview(editor fyne.CanvasObject) *imagePreview {
+	p := &imagePreview{
+		image:  &canvas.Image{FillMode: canvas.ImageFillContain, ScaleMode: canvas.ImageScaleSmooth},
+		status: widget.NewLabel(""),
+		editor: editor,
+		zoom:   1,
+		fit:    true,
+	}
+	p.scroll = container.NewScroll(p.image)
+
+	toolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ZoomOutIcon(), func() { p.scale(1 / zoomStep) }),
+		widget.NewToolbarAction(theme.ZoomInIcon(), func() { p.scale(zoomStep) }),
+		widget.NewToolbarAction(theme.ZoomFitIcon(), p.fitToPane),
+		widget.NewToolbarAction(theme.ViewFullScreenIcon(), p.actualSize),
+	)
+	p.content = container.NewBorder(container.NewBorder(nil, nil, toolbar, nil, p.status), nil, nil, nil, p.scroll)
+	p.content.Hide()
+	return p
+}
+
+// show downloads fullPath and displays it instead of the editor.
+func (p *imagePreview) show(client *sftp.Client, fullPath string) {
+	// The reset shares the critical section with the new path, so a download of an
+	// earlier selection either finishes first or finds the path changed.
+	p.mu.Lock()
+	p.path = fullPath
+	p.image.Image, p.image.Resource = nil, nil
+	p.image.Refresh()
+	p.status.SetText("loading " + path.Base(fullPath) + "...")
+	p.mu.Unlock()
+
+	p.editor.Hide()
+	p.content.Show()
+
+	data, err := readRemoteImage(client, fullPath)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.path != fullPath {
+		return
+	}
+	if err != nil {
+		p.status.SetText(err.Error())
+		return
+	}
+
+	format := "svg"
+	if strings.EqualFold(path.Ext(fullPath), ".svg") {
+		p.image.Resource = fyne.NewStaticResource(path.Base(fullPath), data)
+		p.natural = svgSize(data)
+	} else {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			p.status.SetText(fmt.Sprintf("failed to decode %s: %v", path.Base(fullPath), err))
+			return
+		}
+		if pixels := int64(config.Width) * int64(config.Height); pixels > maxImagePixels {
+			p.status.SetText(fmt.Sprintf("%s is %d×%d, larger than the %d MP preview limit",
+				path.Base(fullPath), config.Width, config.Height, maxImagePixels>>20))
+			return
+		}
+		var img image.Image
+		img, format, err = image.Decode(bytes.NewReader(data))
+		if err != nil {
+			p.status.SetText(fmt.Sprintf("failed to decode %s: %v", path.Base(fullPath), err))
+			return
+		}
+		p.image.Image = img
+		bounds := img.Bounds()
+		p.natural = fyne.NewSize(float32(bounds.Dx()), float32(bounds.Dy()))
+	}
+	p.info = fmt.Sprintf("%s, %.0f×%.0f, %s", format, p.natural.Width, p.natural.Height, formatSize(int64(len(data))))
+	p.fit, p.zoom = true, 1
+	p.layout()
+}
+
+// hide puts the editor back in place of the preview.
+func (p *imagePreview) hide() {
+	p.mu.Lock()
+	p.path = ""
+	p.image.Image, p.image.Resource = nil, nil
+	p.mu.Unlock()
+
+	p.content.Hide()
+	p.editor.Show()
+}
+
+// scale multiplies the zoom by factor, starting from the fitted scale when the image is fitted.
+func (p *imagePreview) scale(factor float32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	zoom := p.zoom
+	if p.fit {
+		zoom = p.fittedScale()
+	}
+	p.fit = false
+	p.zoom = min(max(zoom*factor, minZoom), maxZoom)
+	p.layout()
+}
+
+func (p *imagePreview) actualSize() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fit, p.zoom = false, 1
+	p.layout()
+}
+
+func (p *imagePreview) fitToPane() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fit, p.zoom = true, 1
+	p.layout()
+}
+
+// fittedScale is the scale at which the whole image fits the visible pane.
+func (p *imagePreview) fittedScale() float32 {
+	size := p.scroll.Size()
+	if p.natural.Width == 0 || p.natural.Height == 0 || size.Width == 0 || size.Height == 0 {
+		return 1
+	}
+	return min(size.Width/p.natural.Width, size.Height/p.natural.Height)
+}
+
+// layout sizes the image for the current mode and shows the scale in the status.
+func (p *imagePreview) layout() {
+	scale := "fit"
+	if p.fit {
+		p.image.SetMinSize(fyne.NewSize(1, 1))
+	} else {
+		p.image.SetMinSize(fyne.NewSize(p.natural.Width*p.zoom, p.natural.Height*p.zoom))
+		scale = strconv.Itoa(int(p.zoom*100+0.5)) + "%"
+	}
+	p.scroll.Refresh()
+	p.image.Refresh()
+	p.status.SetText(p.info + ", " + scale)
+}
+
+// readRemoteImage streams a remote image into memory, refusing files over maxImageSize.
+func readRemoteImage(client *sftp.Client, fullPath string) ([]byte, error) {
+	file, err := client.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", fullPath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %v", fullPath, err)
+	}
+	if info.Size() > maxImageSize {
+		return nil, fmt.Errorf("%s is %s, larger than the %s preview limit", path.Base(fullPath),
+			formatSize(info.Size()), formatSize(maxImageSize))
+	}
+
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(io.LimitReader(file, maxImageSize+1)); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", fullPath, err)
+	}
+	if data.Len() > maxImageSize {
+		return nil, fmt.Errorf("%s grew past the %s preview limit", path.Base(fullPath), formatSize(maxImageSize))
+	}
+	return data.Bytes(), nil
+}
+
+// svgSize reads the natural size of an SVG from its width and height or its viewBox.
+func svgSize(data []byte) fyne.Size {
+	var root struct {
+		Width   string `xml:"width,attr"`
+		Height  string `xml:"height,attr"`
+		ViewBox string `xml:"viewBox,attr"`
+	}
+	if err := xml.Unmarshal(data, &root); err == nil {
+		width, werr := strconv.ParseFloat(strings.TrimSuffix(root.Width, "px"), 32)
+		height, herr := strconv.ParseFloat(strings.TrimSuffix(root.Height, "px"), 32)
+		if werr == nil && herr == nil && width > 0 && height > 0 {
+			return fyne.NewSize(float32(width), float32(height))
+		}
+		if box := strings.Fields(strings.ReplaceAll(root.ViewBox, ",", " ")); len(box) == 4 {
+			width, werr = strconv.ParseFloat(box[2], 32)
+			height, herr = strconv.ParseFloat(box[3], 32)
+			if werr == nil && herr == nil && width > 0 && height > 0 {
+				return fyne.NewSize(float32(width), float32(height))
+			}
+		}
+	}
+	return fyne.NewSize(svgDefaultSize, svgDefaultSize)
+}
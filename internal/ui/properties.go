@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// modeBits are the chmod bits in the order the checkboxes are laid out.
+var modeBits = []struct {
+	title string
+	bit   uint32
+}{
+	{"r", 0o400}, {"w", 0o200}, {"x", 0o100},
+	{"r", 0o040}, {"w", 0o020}, {"x", 0o010},
+	{"r", 0o004}, {"w", 0o002}, {"x", 0o001},
+	{"setuid", 0o4000}, {"setgid", 0o2000}, {"sticky", 0o1000},
+}
+
+// showProperties shows the Lstat and Stat results of an entry and applies edited
+// mode, owner, group and mtime, optionally to a whole directory tree.
+func (ft *fileTable) showProperties(info scoutssh.FileInfo) {
+	client := ft.data.sftpClient
+	target := strings.TrimSuffix(ft.entryPath(info), "/")
+
+	go func() {
+		lstat, err := client.Lstat(target)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to stat %s: %v", target, err), ft.ui.fyneWindow)
+			return
+		}
+		stat := lstat
+		if lstat.Mode()&os.ModeSymlink != 0 {
+			if stat, err = client.Stat(target); err != nil {
+				stat = lstat
+			}
+		}
+		ft.propertiesDialog(client, target, lstat, stat)
+	}()
+}
+
+func (ft *fileTable) propertiesDialog(client *sftp.Client, target string, lstat, stat os.FileInfo) {
+	owners := ft.data.owners
+	var uid, gid uint32
+	var atime time.Time
+	if fileStat, ok := stat.Sys().(*sftp.FileStat); ok {
+		uid, gid = fileStat.UID, fileStat.GID
+		atime = time.Unix(int64(fileStat.Atime), 0)
+	}
+	mode := scoutssh.OctalFromFileMode(stat.Mode())
+
+	octalEntry := widget.NewEntry()
+	checks := make([]*widget.Check, len(modeBits))
+	updating := false
+	setMode := func(value uint32) {
+		updating = true
+		defer func() { updating = false }()
+		mode = value
+		octalEntry.SetText(fmt.Sprintf("%04o", value))
+		for i, bit := range modeBits {
+			checks[i].SetChecked(value&bit.bit != 0)
+		}
+	}
+	for i, bit := range modeBits {
+		bit := bit
+		checks[i] = widget.NewCheck(bit.title, func(checked bool) {
+			if updating {
+				return
+			}
+			if checked {
+				setMode(mode | bit.bit)
+			} else {
+				setMode(mode &^ bit.bit)
+			}
+		})
+	}
+	octalEntry.Validator = func(text string) error {
+		if _, err := strconv.ParseUint(text, 8, 32); err != nil || len(text) > 4 {
+			return fmt.Errorf("octal mode expected, e.g. 0644")
+		}
+		return nil
+	}
+	octalEntry.OnChanged = func(text string) {
+		if updating {
+			return
+		}
+		if value, err := strconv.ParseUint(text, 8, 32); err == nil && value <= 0o7777 {
+			setMode(uint32(value))
+		}
+	}
+	setMode(mode)
+
+	ownerEntry := widget.NewEntry()
+	ownerEntry.SetText(owners.User(uid))
+	groupEntry := widget.NewEntry()
+	groupEntry.SetText(owners.Group(gid))
+	mtimeEntry := widget.NewEntry()
+	mtimeEntry.SetText(stat.ModTime().Format(timeLayout))
+	recursiveCheck := widget.NewCheck("apply to everything inside", nil)
+	if !stat.IsDir() {
+		recursiveCheck.Disable()
+	}
+
+	modeGrid := container.NewGridWithColumns(4,
+		widget.NewLabel("owner"), checks[0], checks[1], checks[2],
+		widget.NewLabel("group"), checks[3], checks[4], checks[5],
+		widget.NewLabel("others"), checks[6], checks[7], checks[8],
+		octalEntry, checks[9], checks[10], checks[11],
+	)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Path", widget.NewLabel(target)),
+		widget.NewFormItem("Lstat", widget.NewLabel(describeStat(lstat, owners))),
+	)
+	if lstat.Mode()&os.ModeSymlink != 0 {
+		linkTarget, _ := client.ReadLink(target)
+		form.Append("Link target", widget.NewLabel(linkTarget))
+		form.Append("Stat", widget.NewLabel(describeStat(stat, owners)))
+	}
+	if !atime.IsZero() {
+		form.Append("Accessed", widget.NewLabel(atime.Format(timeLayout)))
+	}
+	form.Append("Mode", modeGrid)
+	form.Append("Owner", ownerEntry)
+	form.Append("Group", groupEntry)
+	form.Append("Modified", mtimeEntry)
+	form.Append("", recursiveCheck)
+
+	original := struct {
+		mode         uint32
+		owner, group string
+		mtime        string
+	}{mode, ownerEntry.Text, groupEntry.Text, mtimeEntry.Text}
+
+	confirm := dialog.NewCustomConfirm("Properties", "Apply", "Close", form, func(ok bool) {
+		if !ok {
+			return
+		}
+		var change scoutssh.AttrChange
+		if mode != original.mode {
+			fileMode := scoutssh.FileModeFromOctal(mode)
+			change.Mode = &fileMode
+			change.ModeAdded = scoutssh.FileModeFromOctal(mode &^ original.mode)
+			change.ModeRemoved = scoutssh.FileModeFromOctal(original.mode &^ mode)
+		}
+		if ownerEntry.Text != original.owner {
+			id, ok := owners.UserID(strings.TrimSpace(ownerEntry.Text))
+			if !ok {
+				dialog.ShowError(fmt.Errorf("unknown user %q", ownerEntry.Text), ft.ui.fyneWindow)
+				return
+			}
+			change.UID = &id
+		}
+		if groupEntry.Text != original.group {
+			id, ok := owners.GroupID(strings.TrimSpace(groupEntry.Text))
+			if !ok {
+				dialog.ShowError(fmt.Errorf("unknown group %q", groupEntry.Text), ft.ui.fyneWindow)
+				return
+			}
+			change.GID = &id
+		}
+		if mtimeEntry.Text != original.mtime {
+			mtime, err := time.ParseInLocation(timeLayout, strings.TrimSpace(mtimeEntry.Text), time.Local)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("modified time must look like %s", timeLayout), ft.ui.fyneWindow)
+				return
+			}
+			change.ModTime = &mtime
+		}
+
+		go func() {
+			err := scoutssh.SetAttributes(client, target, change, recursiveCheck.Checked)
+			ft.data.path.OnSubmitted(trimPath(ft.data.path.Text))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to change attributes:\n%v", err), ft.ui.fyneWindow)
+			}
+		}()
+	}, ft.ui.fyneWindow)
+	confirm.Resize(fyne.NewSize(ft.ui.fyneWindow.Canvas().Size().Width*0.6, confirm.MinSize().Height))
+	confirm.Show()
+}
+
+func describeStat(info os.FileInfo, owners *scoutssh.Owners) string {
+	text := fmt.Sprintf("%s  %s", info.Mode(), formatSize(info.Size()))
+	if fileStat, ok := info.Sys().(*sftp.FileStat); ok {
+		text += fmt.Sprintf("  %s(%d):%s(%d)", owners.User(fileStat.UID), fileStat.UID, owners.Group(fileStat.GID), fileStat.GID)
+	}
+	return text + "  " + info.ModTime().Format(timeLayout)
+}
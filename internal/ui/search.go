@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"goscout/internal/scoutssh"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// maxSearchResults stops a search once this many matches are listed.
+const maxSearchResults = 1000
+
+func (ui *UI) showSearch(params *UIParams) {
+	w := fyne.CurrentApp().NewWindow("GoScout search: " + params.Host)
+
+	rootEntry := widget.NewEntry()
+	rootEntry.SetText(trimPath(params.data.path.Text))
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("file name glob, e.g. *.conf")
+	contentEntry := widget.NewEntry()
+	contentEntry.SetPlaceHolder("text to find inside files")
+	ignoreCaseCheck := widget.NewCheck("ignore case", nil)
+
+	// mu also guards cancel, which the search goroutine clears, and search, the number of the latest search.
+	var (
+		mu      sync.Mutex
+		matches []scoutssh.SearchMatch
+		cancel  context.CancelFunc
+		search  int
+	)
+	status := widget.NewLabel("")
+	results := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(matches)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			mu.Lock()
+			match := matches[i]
+			mu.Unlock()
+			text := match.Path
+			if match.Line > 0 {
+				text = fmt.Sprintf("%s:%d: %s", match.Path, match.Line, strings.TrimSpace(match.Text))
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+	results.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		match := matches[id]
+		mu.Unlock()
+		results.UnselectAll()
+		go ui.openMatch(params, match)
+	}
+
+	var searchButton *widget.Button
+	searchButton = widget.NewButton("Search", func() {
+		mu.Lock()
+		running := cancel
+		mu.Unlock()
+		if running != nil {
+			running()
+			return
+		}
+		options := scoutssh.SearchOptions{
+			Name:       strings.TrimSpace(nameEntry.Text),
+			Content:    contentEntry.Text,
+			IgnoreCase: ignoreCaseCheck.Checked,
+		}
+		if options.Name == "" && options.Content == "" {
+			dialog.ShowInformation("Search", "Enter a file name glob or text to find", w)
+			return
+		}
+
+		ctx, stop := context.WithCancel(context.Background())
+		mu.Lock()
+		search++
+		current := search
+		cancel = stop
+		matches = nil
+		mu.Unlock()
+		results.Refresh()
+		status.SetText("searching...")
+		searchButton.SetText("Stop")
+
+		go func() {
+			err := scoutssh.Search(ctx, params.data.sftpClient, params.data.sshClient, rootEntry.Text, options, func(match scoutssh.SearchMatch) {
+				mu.Lock()
+				matches = append(matches, match)
+				full := len(matches) >= maxSearchResults
+				mu.Unlock()
+				results.Refresh()
+				if full {
+					stop()
+				}
+			})
+
+			mu.Lock()
+			count := len(matches)
+			mu.Unlock()
+			switch {
+			case count >= maxSearchResults:
+				status.SetText(fmt.Sprintf("first %d matches", count))
+			case err == context.Canceled:
+				status.SetText(fmt.Sprintf("%d matches, stopped", count))
+			case err != nil:
+				status.SetText(fmt.Sprintf("%d matches, with errors", count))
+				dialog.ShowError(err, w)
+			default:
+				status.SetText(fmt.Sprintf("%d matches", count))
+			}
+			stop()
+			mu.Lock()
+			last := search == current
+			if last {
+				cancel = nil
+			}
+			mu.Unlock()
+			if last {
+				searchButton.SetText("Search")
+			}
+		}()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("In", rootEntry),
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Content", container.NewBorder(nil, nil, nil, ignoreCaseCheck, contentEntry)),
+	)
+	contentEntry.OnSubmitted = func(string) { searchButton.OnTapped() }
+	nameEntry.OnSubmitted = contentEntry.OnSubmitted
+
+	w.SetOnClosed(func() {
+		mu.Lock()
+		running := cancel
+		mu.Unlock()
+		if running != nil {
+			running()
+		}
+	})
+	w.SetContent(container.NewBorder(
+		form,
+		container.NewBorder(nil, nil, nil, searchButton, status),
+		nil, nil,
+		results,
+	))
+	w.Resize(fyne.NewSize(720, 480))
+	w.Show()
+}
+
+// openMatch loads the matched file into the editor and puts the cursor on the matching line.
+func (ui *UI) openMatch(params *UIParams, match scoutssh.SearchMatch) {
+	params.data.path.OnSubmitted(match.Path)
+	if match.Line > 0 {
+		params.data.CursorRow = match.Line - 1
+		params.data.CursorColumn = 0
+		params.data.Refresh()
+	}
+	ui.fyneWindow.Canvas().Focus(params.data)
+	ui.fyneWindow.RequestFocus()
+}
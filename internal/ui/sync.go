@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/transfer"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+func (ui *UI) showSync(client *sftp.Client, remoteDir string, onDone func()) {
+	w := fyne.CurrentApp().NewWindow("GoScout sync")
+
+	localEntry := widget.NewEntry()
+	localEntry.SetPlaceHolder("local folder")
+	browseButton := widget.NewButton("Browse", func() {
+		folderOpenDialog := dialog.NewFolderOpen(func(list fyne.ListableURI, err error) {
+			if err == nil && list != nil {
+				localEntry.SetText(list.Path())
+			}
+		}, w)
+		folderOpenDialog.Resize(w.Canvas().Size())
+		folderOpenDialog.Show()
+	})
+
+	remoteEntry := widget.NewEntry()
+	remoteEntry.SetText(remoteDir)
+
+	directionSelect := widget.NewSelect([]string{"local ➜ remote", "remote ➜ local"}, nil)
+	directionSelect.SetSelectedIndex(0)
+	compareSelect := widget.NewSelect([]string{"size + mtime", "checksum"}, nil)
+	compareSelect.SetSelectedIndex(0)
+	deleteCheck := widget.NewCheck("delete extraneous files", nil)
+
+	includeEntry := widget.NewEntry()
+	includeEntry.SetPlaceHolder("include globs, comma separated")
+	excludeEntry := widget.NewEntry()
+	excludeEntry.SetPlaceHolder("exclude globs, comma separated, e.g. .git, *.log")
+
+	// plan is the preview of the current inputs; generation counts input changes
+	// so that a preview finishing after an edit is dropped.
+	var (
+		mu         sync.Mutex
+		plan       *transfer.SyncPlan
+		generation int
+	)
+	currentPlan := func() *transfer.SyncPlan {
+		mu.Lock()
+		defer mu.Unlock()
+		return plan
+	}
+	summary := widget.NewLabel("")
+	changes := widget.NewList(
+		func() int {
+			if current := currentPlan(); current != nil {
+				return len(current.Changes)
+			}
+			return 0
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			current := currentPlan()
+			if current == nil || i >= len(current.Changes) {
+				obj.(*widget.Label).SetText("")
+				return
+			}
+			change := current.Changes[i]
+			name := change.Path
+			if change.IsDir {
+				name += "/"
+			}
+			marker := map[transfer.ChangeKind]string{transfer.Add: "➕", transfer.Update: "✏️", transfer.Delete: "🔴"}[change.Kind]
+			text := fmt.Sprintf("%s %s %s", marker, change.Kind, name)
+			if !change.IsDir {
+				text += ", " + formatSize(change.Size)
+			}
+			obj.(*widget.Label).SetText(text)
+		},
+	)
+
+	var applyButton *widget.Button
+	invalidate := func() int {
+		mu.Lock()
+		generation++
+		plan = nil
+		current := generation
+		mu.Unlock()
+		applyButton.Disable()
+		summary.SetText("")
+		changes.Refresh()
+		return current
+	}
+	previewButton := widget.NewButton("Preview", func() {
+		if localEntry.Text == "" || remoteEntry.Text == "" {
+			dialog.ShowInformation("Sync", "Choose both a local folder and a remote path", w)
+			return
+		}
+		options := transfer.SyncOptions{
+			Direction: transfer.SyncDirection(directionSelect.SelectedIndex()),
+			Checksum:  compareSelect.SelectedIndex() == 1,
+			Delete:    deleteCheck.Checked,
+			Include:   splitGlobs(includeEntry.Text),
+			Exclude:   splitGlobs(excludeEntry.Text),
+		}
+		planned := invalidate()
+		summary.SetText("comparing...")
+		localDir, remoteDir := localEntry.Text, remoteEntry.Text
+		go func() {
+			result, err := ui.transfers.PlanSync(client, localDir, remoteDir, options)
+			mu.Lock()
+			if planned != generation {
+				mu.Unlock()
+				return
+			}
+			if err == nil {
+				plan = result
+			}
+			mu.Unlock()
+			changes.Refresh()
+			if err != nil {
+				summary.SetText("")
+				dialog.ShowError(err, w)
+				return
+			}
+			counts := map[transfer.ChangeKind]int{}
+			for _, change := range result.Changes {
+				counts[change.Kind]++
+			}
+			summary.SetText(fmt.Sprintf("%d to add, %d to update, %d to delete", counts[transfer.Add], counts[transfer.Update], counts[transfer.Delete]))
+			if len(result.Changes) > 0 {
+				applyButton.Enable()
+			}
+		}()
+	})
+
+	applyButton = widget.NewButton("Apply", func() {
+		current := currentPlan()
+		if current == nil {
+			return
+		}
+		dialog.ShowConfirm("Sync", summary.Text+"\nApply these changes?", func(ok bool) {
+			// The inputs may have changed while the dialog was open.
+			if !ok || currentPlan() != current {
+				return
+			}
+			applyButton.Disable()
+			go func() {
+				batch, err := ui.transfers.ApplySync(current)
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				ui.showTransfers()
+				err = batch.Wait()
+				if onDone != nil {
+					onDone()
+				}
+				if err != nil {
+					dialog.ShowError(err, w)
+				} else {
+					dialog.ShowInformation("Success", "Sync finished", w)
+				}
+			}()
+		}, w)
+	})
+	applyButton.Disable()
+
+	for _, entry := range []*widget.Entry{localEntry, remoteEntry, includeEntry, excludeEntry} {
+		entry.OnChanged = func(string) { invalidate() }
+	}
+	directionSelect.OnChanged = func(string) { invalidate() }
+	compareSelect.OnChanged = func(string) { invalidate() }
+	deleteCheck.OnChanged = func(bool) { invalidate() }
+
+	form := widget.NewForm(
+		widget.NewFormItem("Local", container.NewBorder(nil, nil, nil, browseButton, localEntry)),
+		widget.NewFormItem("Remote", remoteEntry),
+		widget.NewFormItem("Direction", container.NewHBox(directionSelect, compareSelect, deleteCheck)),
+		widget.NewFormItem("Include", includeEntry),
+		widget.NewFormItem("Exclude", excludeEntry),
+	)
+
+	w.SetContent(container.NewBorder(
+		form,
+		container.NewBorder(nil, nil, nil, container.NewHBox(previewButton, applyButton), summary),
+		nil, nil,
+		changes,
+	))
+	w.Resize(fyne.NewSize(640, 480))
+	w.Show()
+}
+
+func splitGlobs(text string) []string {
+	var globs []string
+	for _, glob := range strings.Split(text, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
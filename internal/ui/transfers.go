@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/transfer"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// startTransfer plans a batch, settles conflicts with the user, queues it and
+// reports the outcome once every job is finished.
+func (ui *UI) startTransfer(plan func() (*transfer.Batch, error), success string, onDone func()) {
+	go func() {
+		batch, err := plan()
+		if err != nil {
+			dialog.ShowError(err, ui.fyneWindow)
+			return
+		}
+		if !ui.resolveConflicts(batch) {
+			return
+		}
+		if err := ui.transfers.Start(batch); err != nil {
+			dialog.ShowError(err, ui.fyneWindow)
+			return
+		}
+		ui.showTransfers()
+
+		err = batch.Wait()
+		if onDone != nil {
+			onDone()
+		}
+		if err != nil {
+			dialog.ShowError(err, ui.fyneWindow)
+		} else {
+			dialog.ShowInformation("Success", success, ui.fyneWindow)
+		}
+	}()
+}
+
+func (ui *UI) showTransfers() {
+	if ui.transfersWindow != nil {
+		ui.transfersWindow.RequestFocus()
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow("GoScout transfers")
+	ui.transfersWindow = w
+
+	jobs := ui.transfers.Jobs()
+	list := widget.NewList(
+		func() int {
+			return len(jobs)
+		},
+		func() fyne.CanvasObject {
+			pause := widget.NewButtonWithIcon("", theme.MediaPauseIcon(), nil)
+			cancel := widget.NewButtonWithIcon("", theme.CancelIcon(), nil)
+			return container.NewBorder(nil, nil, nil, container.NewHBox(pause, cancel),
+				container.NewVBox(widget.NewLabel(""), widget.NewProgressBar(), widget.NewLabel("")))
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			if i >= len(jobs) {
+				return
+			}
+			job := jobs[i]
+			row := obj.(*fyne.Container)
+			info := row.Objects[0].(*fyne.Container)
+			buttons := row.Objects[1].(*fyne.Container)
+
+			arrow := "⬇"
+			if job.Direction == transfer.Upload {
+				arrow = "⬆"
+			}
+			info.Objects[0].(*widget.Label).SetText(arrow + " " + job.Source + " ➜ " + job.Target)
+			progress := info.Objects[1].(*widget.ProgressBar)
+			progress.Max = float64(max(job.Size, 1))
+			progress.SetValue(float64(job.Transferred()))
+			info.Objects[2].(*widget.Label).SetText(jobStatus(job))
+
+			pause := buttons.Objects[0].(*widget.Button)
+			state := job.State()
+			if state == transfer.Paused {
+				pause.SetIcon(theme.MediaPlayIcon())
+				pause.OnTapped = job.Resume
+			} else {
+				pause.SetIcon(theme.MediaPauseIcon())
+				pause.OnTapped = job.Pause
+			}
+			cancel := buttons.Objects[1].(*widget.Button)
+			cancel.OnTapped = job.Cancel
+			if state == transfer.Queued || state == transfer.Running || state == transfer.Paused {
+				pause.Enable()
+				cancel.Enable()
+			} else {
+				pause.Disable()
+				cancel.Disable()
+			}
+		},
+	)
+
+	totalProgress := widget.NewProgressBar()
+	totalLabel := widget.NewLabel("")
+
+	concurrency := widget.NewSelect([]string{"1", "2", "3", "4", "6", "8", "12", "16"}, func(selected string) {
+		n, err := strconv.Atoi(selected)
+		if err != nil {
+			return
+		}
+		ui.transfers.SetConcurrency(n)
+		ui.cfg.TransferConcurrency = n
+		if err := SaveConfig(ui.cfg); err != nil {
+			ui.notifyError(fmt.Sprintf("Failed to save config: %v", err))
+		}
+	})
+	concurrency.Selected = strconv.Itoa(ui.transfers.Concurrency())
+
+	verifyCheck := widget.NewCheck("verify resumed files (sha256)", func(checked bool) {
+		ui.transfers.SetVerifyResume(checked)
+		ui.cfg.VerifyResume = checked
+		if err := SaveConfig(ui.cfg); err != nil {
+			ui.notifyError(fmt.Sprintf("Failed to save config: %v", err))
+		}
+	})
+	verifyCheck.Checked = ui.transfers.VerifyResume()
+
+	preserveCheck := widget.NewCheck("preserve modes, mtimes and symlinks", func(checked bool) {
+		ui.transfers.SetPreserve(checked)
+		ui.cfg.PreserveAttributes = checked
+		if err := SaveConfig(ui.cfg); err != nil {
+			ui.notifyError(fmt.Sprintf("Failed to save config: %v", err))
+		}
+	})
+	preserveCheck.Checked = ui.transfers.Preserve()
+
+	clearButton := widget.NewButton("Clear finished", func() {
+		ui.transfers.Clear()
+	})
+
+	refresh := func() {
+		jobs = ui.transfers.Jobs()
+		list.Refresh()
+
+		transferred, size, speed := ui.transfers.Totals()
+		totalProgress.Max = float64(max(size, 1))
+		totalProgress.SetValue(float64(transferred))
+
+		finished := 0
+		for _, job := range jobs {
+			if state := job.State(); state != transfer.Queued && state != transfer.Running && state != transfer.Paused {
+				finished++
+			}
+		}
+		status := fmt.Sprintf("%d of %d files, %s / %s, %s/s", finished, len(jobs), formatSize(transferred), formatSize(size), formatSize(int64(speed)))
+		if speed > 0 {
+			status += ", ETA " + formatETA(time.Duration(float64(size-transferred)/speed*float64(time.Second)))
+		}
+		totalLabel.SetText(status)
+	}
+	refresh()
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refresh()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	w.SetOnClosed(func() {
+		close(stop)
+		ui.transfersWindow = nil
+	})
+
+	top := container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(widget.NewLabel("parallel"), concurrency, clearButton), totalLabel),
+		container.NewHBox(preserveCheck, verifyCheck),
+		totalProgress,
+	)
+	w.SetContent(container.NewBorder(top, nil, nil, nil, list))
+	w.Resize(fyne.NewSize(640, 400))
+	w.Show()
+}
+
+func jobStatus(job *transfer.Job) string {
+	state := job.State()
+	status := fmt.Sprintf("%s, %s / %s", state, formatSize(job.Transferred()), formatSize(job.Size))
+	switch state {
+	case transfer.Running:
+		status += fmt.Sprintf(", %s/s, ETA %s", formatSize(int64(job.Speed())), formatETA(job.ETA()))
+	case transfer.Failed:
+		status += ": " + job.Err().Error()
+	}
+	return status
+}
+
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
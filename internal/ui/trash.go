@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"fmt"
+	"goscout/internal/scoutssh"
+	"path"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const trashDirName = ".goscout-trash"
+
+// remove counts what the entries contain, asks for confirmation and then deletes them
+// or, when the host has a trash directory, moves them there with an option to undo.
+func (ft *fileTable) remove(infos []scoutssh.FileInfo) {
+	client := ft.data.sftpClient
+	go func() {
+		var stats scoutssh.TreeStats
+		for _, info := range infos {
+			entryStats, err := scoutssh.CountTree(client, ft.entryPath(info))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to inspect %s: %v", displayName(info), err), ft.ui.fyneWindow)
+				return
+			}
+			stats.Add(entryStats)
+		}
+
+		trashDir := ft.ui.cfg.Trash[ft.host]
+		trashEntry := widget.NewEntry()
+		trashEntry.SetText(trashDir)
+		if trashDir == "" {
+			trashEntry.SetText(path.Join(scoutssh.RemoteHome, trashDirName))
+		}
+		trashCheck := widget.NewCheck("move to trash", func(checked bool) {
+			if checked {
+				trashEntry.Enable()
+			} else {
+				trashEntry.Disable()
+			}
+		})
+		trashCheck.SetChecked(trashDir != "")
+		trashCheck.OnChanged(trashCheck.Checked)
+
+		summary := fmt.Sprintf("%d files (%s) in %d folders will be removed:\n%s",
+			stats.Files, formatSize(stats.Size), stats.Dirs, selectionNames(infos))
+		content := container.NewBorder(nil, container.NewBorder(nil, nil, trashCheck, nil, trashEntry), nil, nil,
+			container.NewVScroll(widget.NewLabel(summary)))
+
+		confirm := dialog.NewCustomConfirm("Remove", "Remove", "Cancel", content, func(ok bool) {
+			if !ok {
+				return
+			}
+			trashDir := ""
+			if trashCheck.Checked {
+				trashDir = strings.TrimSpace(trashEntry.Text)
+			}
+			ft.ui.cfg.Trash[ft.host] = trashDir
+			if err := SaveConfig(ft.ui.cfg); err != nil {
+				ft.ui.notifyError(fmt.Sprintf("Failed to save config: %v", err))
+			}
+
+			if trashDir == "" {
+				go ft.forEach("remove", infos, func(info scoutssh.FileInfo) error {
+					_, err := scoutssh.RemoveSFTP(client, ft.entryPath(info))
+					return err
+				})
+				return
+			}
+			go ft.moveToTrash(trashDir, infos)
+		}, ft.ui.fyneWindow)
+		confirm.Resize(fyne.NewSize(ft.ui.fyneWindow.Canvas().Size().Width*0.6, 400))
+		confirm.Show()
+	}()
+}
+
+func (ft *fileTable) moveToTrash(trashDir string, infos []scoutssh.FileInfo) {
+	client := ft.data.sftpClient
+	var trashed []scoutssh.TrashEntry
+	ft.forEach("move to trash", infos, func(info scoutssh.FileInfo) error {
+		entry, err := scoutssh.MoveToTrash(client, trashDir, ft.entryPath(info))
+		if err == nil {
+			trashed = append(trashed, entry)
+		}
+		return err
+	})
+	if len(trashed) == 0 {
+		return
+	}
+
+	message := widget.NewLabel(fmt.Sprintf("%d entries moved to %s", len(trashed), trashDir))
+	dialog.ShowCustomConfirm("Moved to trash", "Undo", "Close", message, func(undo bool) {
+		if !undo {
+			return
+		}
+		go ft.restore(trashed)
+	}, ft.ui.fyneWindow)
+}
+
+func (ft *fileTable) restore(entries []scoutssh.TrashEntry) {
+	var failures []string
+	for _, entry := range entries {
+		if err := scoutssh.RestoreFromTrash(ft.data.sftpClient, entry); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	ft.data.path.OnSubmitted(trimPath(ft.data.path.Text))
+	if len(failures) > 0 {
+		dialog.ShowError(fmt.Errorf("failed to restore %d of %d:\n%s", len(failures), len(entries), strings.Join(failures, "\n")), ft.ui.fyneWindow)
+	}
+}
+
+func (ui *UI) showTrash(params *UIParams) {
+	trashDir := ui.cfg.Trash[params.Host]
+	if trashDir == "" {
+		dialog.ShowInformation("Trash", "Removed files are deleted permanently on "+params.Host+".\nTick \"move to trash\" when removing to keep them in a trash directory.", ui.fyneWindow)
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow("GoScout trash: " + params.Host)
+	client := params.data.sftpClient
+
+	var entries []scoutssh.TrashEntry
+	selected := -1
+	list := widget.NewList(
+		func() int {
+			return len(entries)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			entry := entries[i]
+			origin := entry.Origin
+			if origin == "" {
+				origin = entry.Name
+			}
+			if entry.IsDir {
+				origin += "/"
+			}
+			obj.(*widget.Label).SetText(entry.Deleted.Format("2006-01-02 15:04") + "  " + origin)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+	}
+
+	reload := func() {
+		var err error
+		entries, err = scoutssh.ListTrash(client, trashDir)
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		if err != nil {
+			dialog.ShowError(err, w)
+		}
+	}
+
+	restoreButton := widget.NewButton("Restore", func() {
+		if selected < 0 {
+			return
+		}
+		entry := entries[selected]
+		go func() {
+			if err := scoutssh.RestoreFromTrash(client, entry); err != nil {
+				dialog.ShowError(err, w)
+			}
+			reload()
+			params.data.path.OnSubmitted(trimPath(params.data.path.Text))
+		}()
+	})
+	purgeButton := widget.NewButton("Delete forever", func() {
+		if selected < 0 {
+			return
+		}
+		entry := entries[selected]
+		dialog.ShowConfirm("Delete forever", "Permanently delete "+entry.Origin+"?", func(ok bool) {
+			if !ok {
+				return
+			}
+			go func() {
+				if err := scoutssh.PurgeTrash(client, entry); err != nil {
+					dialog.ShowError(err, w)
+				}
+				reload()
+			}()
+		}, w)
+	})
+	emptyButton := widget.NewButton("Empty trash", func() {
+		dialog.ShowConfirm("Empty trash", fmt.Sprintf("Permanently delete %d entries in %s?", len(entries), trashDir), func(ok bool) {
+			if !ok {
+				return
+			}
+			current := entries
+			go func() {
+				var failures []string
+				for _, entry := range current {
+					if err := scoutssh.PurgeTrash(client, entry); err != nil {
+						failures = append(failures, err.Error())
+					}
+				}
+				reload()
+				if len(failures) > 0 {
+					dialog.ShowError(fmt.Errorf("failed to delete %d of %d:\n%s", len(failures), len(current), strings.Join(failures, "\n")), w)
+				}
+			}()
+		}, w)
+	})
+
+	w.SetContent(container.NewBorder(
+		widget.NewLabel(trashDir),
+		container.NewHBox(restoreButton, purgeButton, emptyButton),
+		nil, nil,
+		list,
+	))
+	w.Resize(fyne.NewSize(640, 400))
+	w.Show()
+	go reload()
+}
@@ -2,7 +2,9 @@ package ui
 
 import (
 	"goscout/internal/scoutssh"
+	"goscout/internal/transfer"
 	"net"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -10,6 +12,7 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"github.com/fyne-io/terminal"
 	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
 )
 
 type TreeObject struct {
@@ -24,20 +27,127 @@ type UI struct {
 	fyneTabs         *container.DocTabs
 	cfg              *Config
 	openTabs         []string
-	activeSFTP       map[int]*sftp.Client
-	ItemStore        map[string]*TreeObject
 	sshConfigEditor  *saveSSHconfig
 	logsLabel        *widget.Entry
 	connectionTab    *container.TabItem
 	bottomConnection *fyne.Container
 	webdavActive     bool
 	webdavListener   net.Listener // Add this field
+	transfers        *transfer.Manager
+	transfersWindow  fyne.Window
+	// tabsMu guards remoteTabs and openTabs, which connecting and disconnecting hosts change off the UI thread.
+	tabsMu     sync.Mutex
+	remoteTabs map[*container.TabItem]*UIParams
+	hovered    *MouseDetectingLabel
 }
 
 type UIParams struct {
+	Host     string
 	Terminal *terminal.Terminal
 	TreeData map[string][]scoutssh.FileInfo
 	data     *CustomEntry
+	table    *fileTable
+	tree     *dirTree
+	preview  *imagePreview
+	// viewerButton opens the selected file in a viewer when the editor cannot show it.
+	viewerButton *widget.Button
+}
+
+type fileTable struct {
+	ui      *UI
+	data    *CustomEntry
+	options *listOptions
+	all     []*TreeObject
+	items   []*TreeObject
+	table   *widget.Table
+	content fyne.CanvasObject
+
+	// dir is the directory listed for host; selected holds entry paths of the multi-selection.
+	host     string
+	dir      string
+	selected map[string]bool
+	anchor   int
+}
+
+// fileViewer pages through a remote file with ReadAt, keeping one page in memory.
+type fileViewer struct {
+	ui     *UI
+	client *sftp.Client
+	path   string
+	file   *sftp.File
+	window fyne.Window
+
+	mu     sync.Mutex
+	size   int64
+	offset int64
+	end    int64
+	// lines maps offsets with a known line number to that number.
+	lines     map[int64]int
+	sampled   int64
+	newlines  int64
+	lastMatch int64
+
+	text   *widget.Label
+	scroll *container.Scroll
+	status *widget.Label
+	slider *widget.Slider
+
+	// follow mode keeps the last lines of a growing file instead of a page.
+	following bool
+	paused    bool
+	followPos int64
+	tail      []string
+	pending   string
+	lineCap   int
+	highlight string
+	tailList  *widget.List
+	stop      chan struct{}
+	// readMu serializes polls, which make their remote calls outside mu.
+	readMu sync.Mutex
+}
+
+// hexViewer dumps a remote file as hex and ASCII, reading chunks on demand
+// and keeping only the most recently used ones.
+type hexViewer struct {
+	file    *sftp.File
+	size    int64
+	mu      sync.Mutex
+	chunks  map[int64][]byte
+	order   []int64
+	loading map[int64]bool
+	list    *widget.List
+	status  *widget.Label
+}
+
+// imagePreview renders a remote image in place of the editor, either fitted
+// to the pane or scaled by zoom from its natural size.
+type imagePreview struct {
+	image   *canvas.Image
+	scroll  *container.Scroll
+	status  *widget.Label
+	content fyne.CanvasObject
+	editor  fyne.CanvasObject
+	mu      sync.Mutex
+	path    string
+	info    string
+	natural fyne.Size
+	zoom    float32
+	fit     bool
+}
+
+type dirTree struct {
+	tree     *widget.Tree
+	client   *sftp.Client
+	data     *CustomEntry
+	children map[string][]string
+	mu       sync.Mutex
+}
+
+type listOptions struct {
+	column       int
+	desc         bool
+	filter       string
+	hideDotfiles bool
 }
 
 type UIComponents struct {
@@ -53,6 +163,8 @@ type CustomEntry struct {
 	widget.Entry
 	path       *widget.Entry
 	sftpClient *sftp.Client
+	sshClient  *ssh.Client
+	owners     *scoutssh.Owners
 }
 
 type saveSSHconfig struct {
@@ -76,17 +188,26 @@ type clickInterceptorRenderer struct {
 }
 
 type Config struct {
-	WindowWidth  float32            `json:"window_width"`
-	WindowHeight float32            `json:"window_height"`
-	SplitOffsets map[string]float64 `json:"split_offsets"`
-	OpenTabs     []string           `json:"open_tabs"`
+	WindowWidth         float32             `json:"window_width"`
+	WindowHeight        float32             `json:"window_height"`
+	SplitOffsets        map[string]float64  `json:"split_offsets"`
+	OpenTabs            []string            `json:"open_tabs"`
+	Bookmarks           map[string][]string `json:"bookmarks"`
+	TransferConcurrency int                 `json:"transfer_concurrency"`
+	VerifyResume        bool                `json:"verify_resume"`
+	PreserveAttributes  bool                `json:"preserve_attributes"`
+	SFTPMaxPacket       int                 `json:"sftp_max_packet"`
+	Trash               map[string]string   `json:"trash"`
 }
 
 type MouseDetectingLabel struct {
 	widget.Label
+	info      scoutssh.FileInfo
 	fullPath  string
 	isBranch  bool
 	entryFile *widget.Entry
 	entryText *CustomEntry
 	ui        *UI
+	table     *fileTable
+	row       int
 }
@@ -3,14 +3,14 @@ package ui
 import (
 	"fmt"
 	"image/png"
-	"io"
 	"log"
-	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"goscout/internal/scoutssh"
+	"goscout/internal/transfer"
 	"goscout/internal/webdav"
 
 	"fyne.io/fyne/v2"
@@ -20,13 +20,14 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	"github.com/pkg/sftp"
 )
 
 const (
 	repo       = "taradaidv/goscout"
 	ver        = "v0.3.3"
 	configFile = ".goscout.json"
+
+	defaultTransferConcurrency = 4
 )
 
 func (ui *UI) SetHosts() {
@@ -45,6 +46,18 @@ func SetupWindow(fyneWindow fyne.Window, cfg *Config) {
 	if cfg.SplitOffsets == nil {
 		cfg.SplitOffsets = make(map[string]float64)
 	}
+	if cfg.Bookmarks == nil {
+		cfg.Bookmarks = make(map[string][]string)
+	}
+	if cfg.Trash == nil {
+		cfg.Trash = make(map[string]string)
+	}
+	if cfg.TransferConcurrency < 1 {
+		cfg.TransferConcurrency = defaultTransferConcurrency
+	}
+	if cfg.SFTPMaxPacket > 0 {
+		scoutssh.MaxPacket = cfg.SFTPMaxPacket
+	}
 
 	ui := &UI{
 		fyneWindow:       fyneWindow,
@@ -52,14 +65,16 @@ func SetupWindow(fyneWindow fyne.Window, cfg *Config) {
 		fyneTabs:         &container.DocTabs{},
 		cfg:              cfg,
 		openTabs:         []string{},
-		activeSFTP:       make(map[int]*sftp.Client),
-		ItemStore:        map[string]*TreeObject{},
 		sshConfigEditor:  nil,
 		logsLabel:        widget.NewMultiLineEntry(),
 		connectionTab:    &container.TabItem{},
 		bottomConnection: &fyne.Container{},
 		webdavActive:     false,
+		transfers:        transfer.NewManager(cfg.TransferConcurrency),
+		remoteTabs:       make(map[*container.TabItem]*UIParams),
 	}
+	ui.transfers.SetVerifyResume(cfg.VerifyResume)
+	ui.transfers.SetPreserve(cfg.PreserveAttributes)
 
 	defer ui.fyneWindow.Close()
 	ui.fyneWindow.SetMainMenu(fyne.NewMainMenu())
@@ -89,10 +104,16 @@ func SetupWindow(fyneWindow fyne.Window, cfg *Config) {
 		ui.saveState()
 	})
 
+	ui.fyneWindow.SetOnDropped(ui.handleDrop)
+
 	ui.fyneTabs.OnClosed = func(tab *container.TabItem) {
-		if tab.Icon == nil {
+		ui.tabsMu.Lock()
+		_, ok := ui.remoteTabs[tab]
+		delete(ui.remoteTabs, tab)
+		ui.tabsMu.Unlock()
+		if ok {
 			ui.saveState()
-		} else {
+		} else if tab == ui.connectionTab {
 			ui.fyneWindow.Close()
 		}
 	}
@@ -141,10 +162,10 @@ func (ui *UI) connectToHost(host string) *container.TabItem {
 		return nil
 	}
 
-	ui.activeSFTP[len(ui.fyneTabs.Items)] = sftpClient
 	ui.log(host, "connected")
 
-	treeData, err := scoutssh.FetchSFTPData(sftpClient, scoutssh.RemoteHome)
+	owners := scoutssh.LookupOwners(sftpClient)
+	treeData, err := scoutssh.FetchSFTPData(sftpClient, owners, scoutssh.RemoteHome)
 	if err != nil {
 		sshClient.Close()
 		sftpClient.Close()
@@ -152,35 +173,49 @@ func (ui *UI) connectToHost(host string) *container.TabItem {
 	}
 
 	params := UIParams{
+		Host:     host,
 		Terminal: terminal,
 		TreeData: treeData,
 		data: &CustomEntry{
 			Entry:      widget.Entry{},
 			path:       &widget.Entry{},
 			sftpClient: sftpClient,
+			sshClient:  sshClient,
+			owners:     owners,
 		},
 	}
 	params.data.Entry.MultiLine = true
 	params.data.Entry.ExtendBaseWidget(params.data)
-	var split *container.Split
+	params.table = ui.newFileTable(host, params.data)
+	params.tree = ui.newDirTree(sftpClient, params.data)
+	params.preview = newImagePreview(container.NewVScroll(params.data))
+	params.viewerButton = widget.NewButtonWithIcon("Open in viewer", theme.VisibilityIcon(), nil)
+	params.viewerButton.Hide()
 	params.data.path.OnSubmitted = func(fullPath string) {
 		params.data.path.SetText(fullPath)
+		params.viewerButton.Hide()
 		if strings.HasSuffix(fullPath, "/") {
-			treeData, err := scoutssh.FetchSFTPData(ui.activeSFTP[ui.fyneTabs.SelectedIndex()], fullPath)
+			treeData, err := scoutssh.FetchSFTPData(params.data.sftpClient, params.data.owners, fullPath)
 			if err != nil {
 				ui.notifyError(fmt.Sprintf("Failed to list files: %v", err))
 				return
 			}
-			params.TreeData = treeData
-
-			split = container.NewHSplit(ui.components(params))
-			split.SetOffset(ui.cfg.SplitOffsets[host])
-			ui.trackSplitOffset(split, host)
-
-			ui.fyneTabs.Items[ui.fyneTabs.SelectedIndex()].Content = container.NewBorder(nil, nil, nil, nil, split)
-			ui.fyneTabs.Refresh()
+			params.table.setData(treeData)
+			params.tree.setData(fullPath, treeData)
+			params.tree.reveal(fullPath)
+		} else if isImage(fullPath) {
+			ui.fyneWindow.Canvas().Unfocus()
+			go params.preview.show(params.data.sftpClient, fullPath)
 		} else {
-			newEntryText := ui.handleSelection(fullPath)
+			params.preview.hide()
+			newEntryText, viewable := ui.handleSelection(params.data.sftpClient, fullPath)
+			if viewable {
+				client := params.data.sftpClient
+				params.viewerButton.OnTapped = func() {
+					go ui.openViewer(client, fullPath)
+				}
+				params.viewerButton.Show()
+			}
 			params.data.SetText(newEntryText.Text)
 			params.data.TextStyle = newEntryText.TextStyle
 			params.data.Refresh()
@@ -188,16 +223,31 @@ func (ui *UI) connectToHost(host string) *container.TabItem {
 	}
 
 	params.data.path.SetText(scoutssh.RemoteHome)
+	params.table.setData(treeData)
+	params.tree.setData(scoutssh.RemoteHome, treeData)
 
-	split = container.NewHSplit(ui.components(params))
+	split := container.NewHSplit(ui.components(params))
 	split.SetOffset(ui.cfg.SplitOffsets[host])
 	ui.trackSplitOffset(split, host)
 
 	remoteTab := container.NewTabItem(host, container.NewBorder(nil, nil, nil, nil, split))
 	ui.fyneTabs.Append(remoteTab)
+	ui.tabsMu.Lock()
+	ui.remoteTabs[remoteTab] = &params
 	ui.openTabs = append(ui.openTabs, host)
+	ui.tabsMu.Unlock()
+	go params.tree.reveal(scoutssh.RemoteHome)
 	return remoteTab
 }
+
+// tabParams returns the state of the host tab tab, if it is one.
+func (ui *UI) tabParams(tab *container.TabItem) (*UIParams, bool) {
+	ui.tabsMu.Lock()
+	defer ui.tabsMu.Unlock()
+	params, ok := ui.remoteTabs[tab]
+	return params, ok
+}
+
 func (ui *UI) trackSplitOffset(split *container.Split, host string) {
 	go func() {
 		ticker := time.NewTicker(1000 * time.Millisecond)
@@ -293,7 +343,7 @@ func (ui *UI) components(params UIParams) (fyne.CanvasObject, fyne.CanvasObject)
 			ui.stopWebDAV()
 			webdavButton.SetText("Start WebDAV")
 		} else {
-			entryPoint, listener := webdav.Mount(ui.activeSFTP[ui.fyneTabs.SelectedIndex()])
+			entryPoint, listener := webdav.Mount(params.data.sftpClient)
 			ui.webdavListener = listener
 			content := container.NewVBox(
 				widget.NewLabel("This is an experimental feature that starts WebDAV on the localhost without creating local folders,\nmeaning the file system is in-memory and available as long as GoScout is running."),
@@ -327,30 +377,52 @@ func (ui *UI) components(params UIParams) (fyne.CanvasObject, fyne.CanvasObject)
 				}
 
 				localBasePath := list.Path()
+				remotePath := params.data.path.Text
+				localPath := filepath.Join(localBasePath, path.Base(remotePath))
+				client := params.data.sftpClient
 
-				err = downloadFileOrDirectory(ui.activeSFTP[ui.fyneTabs.SelectedIndex()], params.data.path.Text, localBasePath)
-				if err != nil {
-					dialog.ShowError(err, ui.fyneWindow)
-				} else {
-					dialog.ShowInformation("Success", params.data.path.Text+"\nSaved in "+localBasePath, ui.fyneWindow)
-				}
+				ui.startTransfer(func() (*transfer.Batch, error) {
+					return ui.transfers.Download(client, remotePath, localPath)
+				}, remotePath+"\nSaved in "+localBasePath, nil)
 			}, ui.fyneWindow)
 
 			screenSize := ui.fyneWindow.Canvas().Size()
 			fileSaveDialog.Resize(screenSize)
 			fileSaveDialog.Show()
 		}),
+		widget.NewToolbarAction(theme.ListIcon(), func() {
+			ui.showTransfers()
+		}),
+		widget.NewToolbarAction(theme.ViewRefreshIcon(), func() {
+			remoteDir := trimPath(params.data.path.Text)
+			ui.showSync(params.data.sftpClient, remoteDir, func() {
+				params.data.path.OnSubmitted(remoteDir)
+			})
+		}),
+		widget.NewToolbarAction(theme.SearchIcon(), func() {
+			ui.showSearch(&params)
+		}),
+		widget.NewToolbarAction(theme.StorageIcon(), func() {
+			ui.showDiskUsage(&params)
+		}),
+		widget.NewToolbarAction(theme.DeleteIcon(), func() {
+			ui.showTrash(&params)
+		}),
 	)
 
 	toolbarContainer := container.NewHBox(
 		rootButton,
 		toolbar,
+		ui.bookmarksButton(params),
 		webdavButton,
 	)
 
+	browser := container.NewVSplit(params.tree.tree, params.table.content)
+	browser.SetOffset(0.3)
+
 	leftContent := container.NewBorder(
 		toolbarContainer, nil, nil, nil,
-		container.NewVScroll(ui.createList(params.TreeData, params.data.path, params.data)),
+		browser,
 	)
 
 	overlay := NewClickInterceptor(ui, params.Terminal)
@@ -359,125 +431,14 @@ func (ui *UI) components(params UIParams) (fyne.CanvasObject, fyne.CanvasObject)
 	termWithOverlay := container.NewStack(params.Terminal, overlay)
 
 	term := container.NewVSplit(
-		container.NewVScroll(params.data),
+		container.NewStack(params.preview.editor, params.preview.content),
 		termWithOverlay,
 	)
 
 	rightContent := container.NewBorder(
-		params.data.path, nil, nil, nil,
+		container.NewBorder(nil, nil, nil, params.viewerButton, params.data.path), nil, nil, nil,
 		term,
 	)
 
 	return leftContent, rightContent
 }
-
-func isDirectory(path string) bool {
-	return strings.HasSuffix(path, "/")
-}
-
-func downloadFileOrDirectory(client *sftp.Client, remotePath, localBasePath string) error {
-	if isDirectory(remotePath) {
-		localDirPath := path.Join(localBasePath, path.Base(remotePath))
-		return downloadDirectory(client, remotePath, localDirPath)
-	} else {
-		localFilePath := path.Join(localBasePath, path.Base(remotePath))
-		return downloadFile(client, remotePath, localFilePath)
-	}
-}
-
-func downloadDirectory(client *sftp.Client, remotePath, localBasePath string) error {
-	entries, err := client.ReadDir(remotePath)
-	if err != nil {
-		return fmt.Errorf("failed to read directory: %v", err)
-	}
-
-	err = os.MkdirAll(localBasePath, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("failed to create local directory: %v", err)
-	}
-
-	for _, entry := range entries {
-		entryRemotePath := path.Join(remotePath, entry.Name())
-		entryLocalPath := path.Join(localBasePath, entry.Name())
-
-		if entry.IsDir() {
-			err = downloadDirectory(client, entryRemotePath, entryLocalPath)
-		} else {
-			err = downloadFile(client, entryRemotePath, entryLocalPath)
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-func downloadFile(client *sftp.Client, remotePath, localPath string) error {
-	srcFile, err := client.Open(remotePath)
-	if err != nil {
-		return fmt.Errorf("failed to open remote file: %v", err)
-	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(localPath)
-	if err != nil {
-		return fmt.Errorf("failed to create local file: %v", err)
-	}
-	defer dstFile.Close()
-
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
-		return fmt.Errorf("failed to copy file: %v", err)
-	}
-
-	return nil
-}
-
-func uploadDirectory(client *sftp.Client, localPath, remoteBasePath string) error {
-	entries, err := os.ReadDir(localPath)
-	if err != nil {
-		return fmt.Errorf("failed to read local directory: %v", err)
-	}
-
-	err = client.MkdirAll(remoteBasePath)
-	if err != nil {
-		return fmt.Errorf("failed to create remote directory: %v", err)
-	}
-
-	for _, entry := range entries {
-		entryLocalPath := path.Join(localPath, entry.Name())
-		entryRemotePath := path.Join(remoteBasePath, entry.Name())
-
-		if entry.IsDir() {
-			err = uploadDirectory(client, entryLocalPath, entryRemotePath)
-		} else {
-			err = uploadFile(client, entryLocalPath, entryRemotePath)
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
-func uploadFile(client *sftp.Client, localPath, remotePath string) error {
-	srcFile, err := os.Open(localPath)
-	if err != nil {
-		return fmt.Errorf("failed to open local file: %v", err)
-	}
-	defer srcFile.Close()
-
-	dstFile, err := client.Create(remotePath)
-	if err != nil {
-		return fmt.Errorf("failed to create remote file: %v", err)
-	}
-	defer dstFile.Close()
-
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
-		return fmt.Errorf("failed to copy file: %v", err)
-	}
-
-	return nil
-}
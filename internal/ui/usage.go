@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"goscout/internal/scoutssh"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var usageOrders = []struct {
+	title string
+	less  func(a, b *scoutssh.UsageNode) bool
+}{
+	{"size", func(a, b *scoutssh.UsageNode) bool { return a.Size > b.Size }},
+	{"files", func(a, b *scoutssh.UsageNode) bool { return a.Files > b.Files }},
+	{"name", func(a, b *scoutssh.UsageNode) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }},
+}
+
+// showDiskUsage measures a remote directory and shows each child as a bar sized
+// relative to the largest one; directories can be drilled into.
+func (ui *UI) showDiskUsage(params *UIParams) {
+	w := fyne.CurrentApp().NewWindow("GoScout disk usage: " + params.Host)
+
+	var current *scoutssh.UsageNode
+	pathLabel := widget.NewLabel("")
+	status := widget.NewLabel("")
+
+	rows := widget.NewList(
+		func() int {
+			if current == nil {
+				return 0
+			}
+			return len(current.Children)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewProgressBar()
+		},
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			child := current.Children[i]
+			bar := obj.(*widget.ProgressBar)
+			bar.Max = float64(largestChild(current))
+			if bar.Max == 0 {
+				bar.Max = 1
+			}
+			name := child.Name
+			if child.IsDir {
+				name += "/"
+			}
+			text := fmt.Sprintf("%s  %s  %d files", name, formatSize(child.Size), child.Files)
+			bar.TextFormatter = func() string { return text }
+			bar.SetValue(float64(child.Size))
+		},
+	)
+
+	orderSelect := widget.NewSelect(nil, nil)
+	for _, order := range usageOrders {
+		orderSelect.Options = append(orderSelect.Options, order.title)
+	}
+
+	show := func(node *scoutssh.UsageNode) {
+		current = node
+		node.Sort(usageOrders[orderSelect.SelectedIndex()].less)
+		pathLabel.SetText(fmt.Sprintf("%s  %s in %d files", node.Path, formatSize(node.Size), node.Files))
+		rows.UnselectAll()
+		rows.Refresh()
+		rows.ScrollToTop()
+	}
+	orderSelect.OnChanged = func(string) {
+		if current != nil {
+			show(current)
+		}
+	}
+	orderSelect.SetSelectedIndex(0)
+
+	rows.OnSelected = func(id widget.ListItemID) {
+		child := current.Children[id]
+		if child.IsDir && len(child.Children) > 0 {
+			show(child)
+			return
+		}
+		rows.UnselectAll()
+	}
+	upButton := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		if current != nil && current.Parent != nil {
+			show(current.Parent)
+		}
+	})
+	openButton := widget.NewButton("Open in browser", func() {
+		if current != nil {
+			go params.data.path.OnSubmitted(strings.TrimSuffix(current.Path, "/") + "/")
+		}
+	})
+
+	rootEntry := widget.NewEntry()
+	rootEntry.SetText(trimPath(params.data.path.Text))
+	var cancel context.CancelFunc
+	var scanButton *widget.Button
+	scanButton = widget.NewButton("Scan", func() {
+		if cancel != nil {
+			cancel()
+			return
+		}
+		ctx, stop := context.WithCancel(context.Background())
+		cancel = stop
+		scanButton.SetText("Stop")
+		status.SetText("scanning...")
+
+		go func() {
+			root, err := scoutssh.DiskUsage(ctx, params.data.sftpClient, params.data.sshClient, rootEntry.Text, func(scanned int64) {
+				status.SetText(fmt.Sprintf("scanning... %d entries", scanned))
+			})
+			stop()
+			cancel = nil
+			scanButton.SetText("Scan")
+			switch {
+			case err == context.Canceled:
+				status.SetText("stopped")
+			case err != nil:
+				status.SetText("")
+				dialog.ShowError(err, w)
+			default:
+				status.SetText("")
+				show(root)
+			}
+		}()
+	})
+	rootEntry.OnSubmitted = func(string) { scanButton.OnTapped() }
+
+	w.SetOnClosed(func() {
+		if cancel != nil {
+			cancel()
+		}
+	})
+	w.SetContent(container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil, scanButton, rootEntry),
+			container.NewBorder(nil, nil, upButton, container.NewHBox(orderSelect, openButton), pathLabel),
+		),
+		status,
+		nil, nil,
+		rows,
+	))
+	w.Resize(fyne.NewSize(720, 520))
+	w.Show()
+	scanButton.OnTapped()
+}
+
+func largestChild(node *scoutssh.UsageNode) int64 {
+	var largest int64
+	for _, child := range node.Children {
+		if child.Size > largest {
+			largest = child.Size
+		}
+	}
+	return largest
+}
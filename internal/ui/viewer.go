@@ -0,0 +1,358 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+const (
+	// viewerPageSize is how much of the file one page shows.
+	viewerPageSize = 64 * 1024
+	// viewerSearchChunk is how much is read at a time while searching.
+	viewerSearchChunk = 1 << 20
+)
+
+// showViewer opens a read-only window that shows one page of a remote file at a time.
+func (ui *UI) showViewer(client *sftp.Client, fullPath string) {
+	file, err := client.Open(fullPath)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to open %s: %v", fullPath, err), ui.fyneWindow)
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		dialog.ShowError(fmt.Errorf("failed to stat %s: %v", fullPath, err), ui.fyneWindow)
+		return
+	}
+
+	v := &fileViewer{
+		ui:        ui,
+		client:    client,
+		path:      fullPath,
+		file:      file,
+		window:    fyne.CurrentApp().NewWindow("GoScout viewer: " + path.Base(fullPath)),
+		size:      info.Size(),
+		lines:     map[int64]int{0: 1},
+		lastMatch: -1,
+		text:      widget.NewLabel(""),
+		status:    widget.NewLabel(""),
+		slider:    widget.NewSlider(0, float64(info.Size())),
+		lineCap:   followLineCaps[0],
+	}
+	v.tailList = v.newTailList()
+	v.tailList.Hide()
+	v.text.TextStyle.Monospace = true
+	v.scroll = container.NewScroll(v.text)
+	v.slider.OnChangeEnded = func(value float64) {
+		go v.load(int64(value), false)
+	}
+
+	lineEntry := widget.NewEntry()
+	lineEntry.SetPlaceHolder("line")
+	lineEntry.OnSubmitted = func(text string) {
+		if line, err := strconv.Atoi(strings.TrimSpace(text)); err == nil && line > 0 {
+			go v.load(v.estimateOffset(line), false)
+		}
+	}
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("search")
+	ignoreCaseCheck := widget.NewCheck("ignore case", nil)
+	searchEntry.OnSubmitted = func(text string) {
+		if text != "" {
+			go v.search(text, ignoreCaseCheck.Checked)
+		}
+	}
+	searchEntry.OnChanged = func(string) {
+		v.mu.Lock()
+		v.lastMatch = -1
+		v.mu.Unlock()
+	}
+
+	toolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.MediaSkipPreviousIcon(), func() { go v.load(0, true) }),
+		widget.NewToolbarAction(theme.MediaFastRewindIcon(), func() { go v.previousPage() }),
+		widget.NewToolbarAction(theme.MediaFastForwardIcon(), func() { go v.nextPage() }),
+		widget.NewToolbarAction(theme.MediaSkipNextIcon(), func() { go v.load(v.lastPage(), false) }),
+	)
+	controls := container.NewBorder(nil, nil, toolbar,
+		container.NewHBox(container.NewGridWrap(fyne.NewSize(90, lineEntry.MinSize().Height), lineEntry), ignoreCaseCheck),
+		searchEntry)
+
+	var pauseCheck *widget.Check
+	followCheck := widget.NewCheck("follow", func(checked bool) {
+		if checked {
+			pauseCheck.Enable()
+			go v.startFollow()
+		} else {
+			pauseCheck.SetChecked(false)
+			pauseCheck.Disable()
+			go v.stopFollow()
+		}
+	})
+	pauseCheck = widget.NewCheck("pause", func(checked bool) {
+		go v.setPaused(checked)
+	})
+	pauseCheck.Disable()
+
+	var caps []string
+	for _, lineCap := range followLineCaps {
+		caps = append(caps, fmt.Sprintf("%d lines", lineCap))
+	}
+	capSelect := widget.NewSelect(caps, nil)
+	capSelect.OnChanged = func(string) {
+		v.mu.Lock()
+		v.lineCap = followLineCaps[capSelect.SelectedIndex()]
+		v.mu.Unlock()
+	}
+	capSelect.SetSelectedIndex(0)
+
+	highlightEntry := widget.NewEntry()
+	highlightEntry.SetPlaceHolder("highlight lines containing")
+	highlightEntry.OnChanged = func(text string) {
+		v.mu.Lock()
+		v.highlight = text
+		v.mu.Unlock()
+		v.tailList.Refresh()
+	}
+	followControls := container.NewBorder(nil, nil, container.NewHBox(followCheck, pauseCheck, capSelect), nil, highlightEntry)
+
+	v.window.SetOnClosed(func() {
+		v.mu.Lock()
+		if v.following {
+			v.following = false
+			close(v.stop)
+		}
+		v.file.Close()
+		v.mu.Unlock()
+	})
+	v.window.SetContent(container.NewBorder(
+		container.NewVBox(controls, v.slider, followControls),
+		v.status,
+		nil, nil,
+		container.NewStack(v.scroll, v.tailList),
+	))
+	v.window.Resize(fyne.NewSize(900, 640))
+	v.window.Show()
+	v.load(0, true)
+}
+
+// load shows the page starting at offset when exact is set, which callers use for
+// line starts and for continuing a line longer than a page, and otherwise at the
+// first line that begins at or after offset.
+func (v *fileViewer) load(offset int64, exact bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > v.size {
+		offset = v.size
+	}
+
+	if offset > 0 && !exact {
+		previous := make([]byte, 1)
+		if _, err := v.file.ReadAt(previous, offset-1); err == nil && previous[0] != '\n' {
+			// Within the last line there is no next line, so its tail is shown.
+			if next := nextLineStart(v.file, v.size, offset); next < v.size {
+				offset = next
+			}
+		}
+	}
+
+	page := make([]byte, viewerPageSize)
+	n, err := v.file.ReadAt(page, offset)
+	if err != nil && err != io.EOF {
+		v.status.SetText(fmt.Sprintf("failed to read: %v", err))
+		return
+	}
+	page = page[:n]
+	if end := offset + int64(n); end < v.size {
+		if i := bytes.LastIndexByte(page, '\n'); i >= 0 {
+			page = page[:i+1]
+		}
+	}
+
+	v.offset, v.end = offset, offset+int64(len(page))
+	newlines := bytes.Count(page, []byte{'\n'})
+	v.sampled += int64(len(page))
+	v.newlines += int64(newlines)
+	if line, ok := v.lines[v.offset]; ok {
+		v.lines[v.end] = line + newlines
+	}
+
+	v.text.SetText(strings.ToValidUTF8(strings.ReplaceAll(string(page), "\t", "    "), "�"))
+	v.scroll.ScrollToTop()
+	v.slider.Max = float64(v.size)
+	v.slider.Value = float64(v.offset)
+	v.slider.Refresh()
+	v.status.SetText(v.describe(newlines))
+}
+
+// nextLineStart returns the offset just past the next newline at or after offset
+// in file, which is size bytes long.
+func nextLineStart(file *sftp.File, size, offset int64) int64 {
+	chunk := make([]byte, 4096)
+	for offset < size {
+		n, err := file.ReadAt(chunk, offset)
+		if i := bytes.IndexByte(chunk[:n], '\n'); i >= 0 {
+			return offset + int64(i) + 1
+		}
+		offset += int64(n)
+		if err != nil {
+			break
+		}
+	}
+	return size
+}
+
+func (v *fileViewer) nextPage() {
+	v.mu.Lock()
+	end, size := v.end, v.size
+	v.mu.Unlock()
+	if end < size {
+		v.load(end, true)
+	}
+}
+
+// previousPage shows the lines before the current page, or the part of a line
+// longer than a page that comes before it.
+func (v *fileViewer) previousPage() {
+	v.mu.Lock()
+	file, size, offset := v.file, v.size, v.offset
+	v.mu.Unlock()
+	start := offset - viewerPageSize
+	if start > 0 {
+		if lineStart := nextLineStart(file, size, start); lineStart < offset {
+			start = lineStart
+		}
+	}
+	v.load(start, true)
+}
+
+// lineStartBefore returns where the line holding offset begins, looking back at
+// most viewerPageSize bytes; -1 means the line starts further back.
+func (v *fileViewer) lineStartBefore(offset int64) int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	from := max(offset-viewerPageSize, 0)
+	chunk := make([]byte, offset-from)
+	n, _ := v.file.ReadAt(chunk, from)
+	if i := bytes.LastIndexByte(chunk[:n], '\n'); i >= 0 {
+		return from + int64(i) + 1
+	}
+	if from == 0 {
+		return 0
+	}
+	return -1
+}
+
+func (v *fileViewer) lastPage() int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.size - viewerPageSize + 1
+}
+
+// describe reports the shown range; line numbers are exact when the page was reached
+// by paging from the start and estimated from the average line length otherwise.
+func (v *fileViewer) describe(newlines int) string {
+	position := fmt.Sprintf("%s–%s of %s", formatSize(v.offset), formatSize(v.end), formatSize(v.size))
+	if line, ok := v.lines[v.offset]; ok {
+		return fmt.Sprintf("lines %d–%d, %s", line, line+newlines, position)
+	}
+	line := v.estimateLine(v.offset)
+	return fmt.Sprintf("lines ~%d–~%d, %s", line, line+newlines, position)
+}
+
+func (v *fileViewer) averageLine() float64 {
+	if v.newlines == 0 {
+		return float64(viewerPageSize)
+	}
+	return float64(v.sampled) / float64(v.newlines)
+}
+
+func (v *fileViewer) estimateLine(offset int64) int {
+	return int(float64(offset)/v.averageLine()) + 1
+}
+
+func (v *fileViewer) estimateOffset(line int) int64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for offset, known := range v.lines {
+		if known == line {
+			return offset
+		}
+	}
+	return int64(float64(line-1) * v.averageLine())
+}
+
+// search reads forward from the last match in bounded chunks and shows the page starting at the matching line.
+func (v *fileViewer) search(needle string, ignoreCase bool) {
+	v.mu.Lock()
+	file, size, from := v.file, v.size, v.offset
+	if v.lastMatch >= 0 {
+		from = v.lastMatch + 1
+	}
+	v.mu.Unlock()
+
+	pattern := []byte(needle)
+	if ignoreCase {
+		pattern = asciiLower(pattern)
+	}
+	v.status.SetText("searching...")
+
+	chunk := make([]byte, viewerSearchChunk)
+	for offset := from; offset < size; {
+		n, err := file.ReadAt(chunk, offset)
+		data := chunk[:n]
+		if ignoreCase {
+			data = asciiLower(data)
+		}
+		if i := bytes.Index(data, pattern); i >= 0 {
+			match := offset + int64(i)
+			lineStart := v.lineStartBefore(match)
+			if lineStart < 0 {
+				// The line is too long to show from its start, so the page starts at the match.
+				lineStart = match
+			}
+			v.mu.Lock()
+			v.lastMatch = match
+			v.mu.Unlock()
+			v.load(lineStart, true)
+			return
+		}
+		if err != nil || n < len(pattern) {
+			break
+		}
+		offset += int64(n - len(pattern) + 1)
+	}
+
+	v.mu.Lock()
+	v.lastMatch = -1
+	v.mu.Unlock()
+	v.status.SetText(fmt.Sprintf("%q not found after %s", needle, formatSize(from)))
+}
+
+// asciiLower folds ASCII letters only, so offsets in the folded copy match the file.
+func asciiLower(b []byte) []byte {
+	folded := make([]byte, len(b))
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		folded[i] = c
+	}
+	return folded
+}